@@ -79,7 +79,11 @@ func runDetect(context detect.Detect) (int, error) {
 	}
 
 	webDir := phpweb.PickWebDir(buildpackYAML)
-	version := phpweb.Version(context.Buildpack)
+	version, versionSource, err := phpweb.Version(context.Buildpack, buildpackYAML, context.Application.Root, context.Logger)
+	if err != nil {
+		return context.Fail(), err
+	}
+
 	isWebApp, err := phpweb.SearchForWebApp(context.Application.Root, webDir)
 	if err != nil {
 		return context.Fail(), err
@@ -101,7 +105,7 @@ func runDetect(context detect.Detect) (int, error) {
 			},
 		},
 		Requires: []buildplan.Required{
-			requiredPHP(version),
+			requiredPHP(version, versionSource),
 			{
 				Name: phpweb.Dependency,
 			},
@@ -125,14 +129,14 @@ func runDetect(context detect.Detect) (int, error) {
 	return context.Pass(plan)
 }
 
-func requiredPHP(version string) buildplan.Required {
+func requiredPHP(version string, versionSource string) buildplan.Required {
 	return buildplan.Required{
 		Name:    "php",
 		Version: version,
 		Metadata: buildplan.Metadata{
 			"launch":                    true,
 			"build":                     true,
-			buildpackplan.VersionSource: "default-versions",
+			buildpackplan.VersionSource: versionSource,
 		},
 	}
 }
@@ -130,6 +130,53 @@ func testDetect(t *testing.T, when spec.G, it spec.S) {
 				},
 			}))
 		})
+
+		it("requires the PHP version of composer.json", func() {
+			test.WriteFile(t, filepath.Join(factory.Detect.Application.Root, "htdocs", "index.php"), "")
+			test.WriteFile(t, filepath.Join(factory.Detect.Application.Root, "composer.json"), `{"require": {"php": ">=7.3 <8.0"}}`)
+			factory.Detect.Buildpack.Metadata = map[string]interface{}{"default_version": "php.default.version"}
+
+			Expect(runDetect(factory.Detect)).To(Equal(detect.PassStatusCode))
+			Expect(factory.Plans.Plan.Requires[0]).To(Equal(buildplan.Required{
+				Name:    "php",
+				Version: ">=7.3, <8.0",
+				Metadata: buildplan.Metadata{"launch": true, "build": true,
+					buildpackplan.VersionSource: phpweb.VersionSourceComposerJSON},
+			}))
+		})
+
+		it("requires the PHP version of buildpack.yml", func() {
+			test.WriteFile(t, filepath.Join(factory.Detect.Application.Root, "htdocs", "index.php"), "")
+			test.WriteFile(t, filepath.Join(factory.Detect.Application.Root, "buildpack.yml"), `{"php": {"version": "7.4.*"}}`)
+
+			Expect(runDetect(factory.Detect)).To(Equal(detect.PassStatusCode))
+			Expect(factory.Plans.Plan.Requires[0]).To(Equal(buildplan.Required{
+				Name:    "php",
+				Version: "7.4.*",
+				Metadata: buildplan.Metadata{"launch": true, "build": true,
+					buildpackplan.VersionSource: phpweb.VersionSourceBuildpackYAML},
+			}))
+		})
+	})
+
+	when("buildpack.yml is invalid", func() {
+		it("fails listing the supported web servers", func() {
+			test.WriteFile(t, filepath.Join(factory.Detect.Application.Root, "htdocs", "index.php"), "")
+			test.WriteFile(t, filepath.Join(factory.Detect.Application.Root, "buildpack.yml"), `{"php": {"webserver": "caddy"}}`)
+
+			code, err := runDetect(factory.Detect)
+			Expect(code).To(Equal(detect.FailStatusCode))
+			Expect(err).To(MatchError(`invalid php.webserver "caddy", must be one of: php-server, httpd, nginx`))
+		})
+
+		it("fails when the web directory is outside of the application", func() {
+			test.WriteFile(t, filepath.Join(factory.Detect.Application.Root, "index.php"), "")
+			test.WriteFile(t, filepath.Join(factory.Detect.Application.Root, "buildpack.yml"), `{"php": {"webdirectory": "../../etc"}}`)
+
+			code, err := runDetect(factory.Detect)
+			Expect(code).To(Equal(detect.FailStatusCode))
+			Expect(err).To(MatchError(`invalid php.webdirectory "../../etc", must be a relative path within the application`))
+		})
 	})
 
 	when("there is a PHP script", func() {
@@ -20,15 +20,35 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"time"
 
 	"github.com/paketo-buildpacks/php-web/procmgr"
 )
 
+var (
+	// restartDelay is the pause before restarting a process which exited, so a failing process doesn't spin
+	restartDelay = time.Second
+
+	// watchInterval is how often the directories watched for configuration changes are polled
+	watchInterval = time.Second
+)
+
 func main() {
+	if len(os.Args) == 3 && os.Args[1] == "--schedule" {
+		jobs, err := procmgr.ReadSchedule(os.Args[2])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error loading/parsing schedule file:", err)
+			os.Exit(2)
+		}
+
+		runSchedule(jobs)
+	}
+
 	if len(os.Args) != 2 {
 		fmt.Fprintln(os.Stderr)
 		fmt.Fprintln(os.Stderr, "USAGE:")
 		fmt.Fprintln(os.Stderr, "    procmgr <path-to-proc-file>")
+		fmt.Fprintln(os.Stderr, "    procmgr --schedule <path-to-schedule-file>")
 		fmt.Fprintln(os.Stderr)
 		os.Exit(1)
 	}
@@ -55,7 +75,14 @@ func runProcs(procs procmgr.Procs) error {
 	msgs := make(chan procMsg)
 
 	for procName, proc := range procs.Processes {
-		go runProc(procName, proc, msgs)
+		if proc.Replicas <= 1 {
+			go runProc(procName, proc, msgs)
+			continue
+		}
+
+		for replica := 1; replica <= proc.Replicas; replica++ {
+			go runProc(fmt.Sprintf("%s-%d", procName, replica), proc, msgs)
+		}
 	}
 
 	msg := <-msgs
@@ -64,19 +91,101 @@ func runProcs(procs procmgr.Procs) error {
 }
 
 func runProc(procName string, proc procmgr.Proc, msgs chan procMsg) {
-	cmd := exec.Command(proc.Command, proc.Args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	for {
+		cmd := exec.Command(proc.Command, proc.Args...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		err := cmd.Start()
+		if err != nil {
+			msgs <- procMsg{procName, cmd, err}
+			return
+		}
+
+		done := make(chan struct{})
+		if len(proc.Watch) > 0 {
+			go watchProc(procName, proc, cmd.Process, done)
+		}
+
+		err = cmd.Wait()
+		close(done)
+		if !proc.Restart {
+			msgs <- procMsg{procName, cmd, err}
+			return
+		}
 
-	err := cmd.Start()
+		fmt.Fprintln(os.Stderr, "process", procName, "exited, status:", cmd.ProcessState, "restarting")
+		time.Sleep(restartDelay)
+	}
+}
+
+// watchProc signals the process to reload its configuration when a watched directory changes, until done is closed
+func watchProc(procName string, proc procmgr.Proc, process *os.Process, done chan struct{}) {
+	signal, err := procmgr.ParseReloadSignal(proc.ReloadSignal)
 	if err != nil {
-		msgs <- procMsg{procName, cmd, err}
+		fmt.Fprintln(os.Stderr, "not watching process", procName, "for changes:", err)
+		return
 	}
 
-	err = cmd.Wait()
+	state, err := procmgr.WatchState(proc.Watch)
 	if err != nil {
-		msgs <- procMsg{procName, cmd, err}
+		fmt.Fprintln(os.Stderr, "not watching process", procName, "for changes:", err)
+		return
+	}
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			current, err := procmgr.WatchState(proc.Watch)
+			if err != nil || current == state {
+				continue
+			}
+			state = current
+
+			fmt.Fprintln(os.Stderr, "configuration of process", procName, "changed, reloading")
+			if err := process.Signal(signal); err != nil {
+				fmt.Fprintln(os.Stderr, "failed to reload process", procName, ":", err)
+			}
+		}
+	}
+}
+
+// runSchedule runs the jobs due at the start of each minute, it never returns
+func runSchedule(jobs []procmgr.ScheduledJob) {
+	for {
+		next := time.Now().Truncate(time.Minute).Add(time.Minute)
+		time.Sleep(time.Until(next))
+
+		for _, job := range dueJobs(jobs, next) {
+			go runJob(job)
+		}
+	}
+}
+
+func dueJobs(jobs []procmgr.ScheduledJob, t time.Time) []procmgr.ScheduledJob {
+	var due []procmgr.ScheduledJob
+	for _, job := range jobs {
+		if job.Matches(t) {
+			due = append(due, job)
+		}
 	}
+	return due
+}
+
+// runJob runs a scheduled job, a failing job is logged but does not stop the scheduler
+func runJob(job procmgr.ScheduledJob) error {
+	cmd := exec.Command("bash", "-c", job.Command)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
 
-	msgs <- procMsg{procName, cmd, nil}
+	err := cmd.Run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "scheduled job", job.Command, "failed:", err)
+	}
+	return err
 }
@@ -17,7 +17,13 @@
 package main
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/paketo-buildpacks/php-web/procmgr"
 
@@ -31,8 +37,17 @@ func TestUnitProcmgr(t *testing.T) {
 }
 
 func testProcmgr(t *testing.T, _ spec.G, it spec.S) {
+	var defaultRestartDelay, defaultWatchInterval time.Duration
+
 	it.Before(func() {
 		RegisterTestingT(t)
+		defaultRestartDelay = restartDelay
+		defaultWatchInterval = watchInterval
+	})
+
+	it.After(func() {
+		restartDelay = defaultRestartDelay
+		watchInterval = defaultWatchInterval
 	})
 
 	it("should run a proc", func() {
@@ -102,4 +117,94 @@ func testProcmgr(t *testing.T, _ spec.G, it spec.S) {
 		})
 		Expect(err).ToNot(HaveOccurred())
 	})
+
+	it("should run the replicas of a proc", func() {
+		output := filepath.Join(t.TempDir(), "output")
+
+		err := runProcs(procmgr.Procs{
+			Processes: map[string]procmgr.Proc{
+				"worker": {
+					Command:  "bash",
+					Args:     []string{"-c", fmt.Sprintf("echo started >> %s; sleep 0.25", output)},
+					Replicas: 3,
+				},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		contents, err := ioutil.ReadFile(output)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(strings.Count(string(contents), "started")).To(Equal(3))
+	})
+
+	it("should restart a proc which exits, instead of stopping", func() {
+		restartDelay = 10 * time.Millisecond
+		output := filepath.Join(t.TempDir(), "output")
+
+		err := runProcs(procmgr.Procs{
+			Processes: map[string]procmgr.Proc{
+				"worker": {
+					Command: "bash",
+					Args:    []string{"-c", fmt.Sprintf("echo started >> %s; exit 1", output)},
+					Restart: true,
+				},
+				"sleep0.25": {
+					Command: "sleep",
+					Args:    []string{"0.25"},
+				},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		contents, err := ioutil.ReadFile(output)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(strings.Count(string(contents), "started")).To(BeNumerically(">", 1))
+	})
+
+	it("should signal a proc to reload when a watched directory changes", func() {
+		watchInterval = 10 * time.Millisecond
+		watched, output := t.TempDir(), filepath.Join(t.TempDir(), "output")
+
+		// moved into place, as a poll between creating & writing the file would see two changes
+		staged := filepath.Join(t.TempDir(), "custom.conf")
+		Expect(ioutil.WriteFile(staged, []byte("changed"), 0644)).To(Succeed())
+
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			Expect(os.Rename(staged, filepath.Join(watched, "custom.conf"))).To(Succeed())
+		}()
+
+		err := runProcs(procmgr.Procs{
+			Processes: map[string]procmgr.Proc{
+				"server": {
+					Command:      "bash",
+					Args:         []string{"-c", fmt.Sprintf("trap 'echo reloaded >> %s' HUP; for i in $(seq 20); do sleep 0.05; done", output)},
+					Watch:        []string{watched, filepath.Join(watched, "missing")},
+					ReloadSignal: "SIGHUP",
+				},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		contents, err := ioutil.ReadFile(output)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(strings.Count(string(contents), "reloaded")).To(Equal(1))
+	})
+
+	it("should select the scheduled jobs which are due", func() {
+		hourly, err := procmgr.ParseScheduledJob("0 * * * * echo hourly")
+		Expect(err).ToNot(HaveOccurred())
+		everyMinute, err := procmgr.ParseScheduledJob("* * * * * echo every minute")
+		Expect(err).ToNot(HaveOccurred())
+
+		jobs := []procmgr.ScheduledJob{hourly, everyMinute}
+		Expect(dueJobs(jobs, time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC))).To(Equal(jobs))
+		Expect(dueJobs(jobs, time.Date(2020, 1, 1, 10, 1, 0, 0, time.UTC))).To(Equal([]procmgr.ScheduledJob{everyMinute}))
+	})
+
+	it("should report a failing scheduled job", func() {
+		job, err := procmgr.ParseScheduledJob("* * * * * exit 3")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(runJob(job)).To(MatchError("exit status 3"))
+	})
 }
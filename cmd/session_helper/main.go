@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"log"
+	"os"
 	"strings"
 
 	"github.com/paketo-buildpacks/php-web/features"
@@ -13,7 +14,7 @@ func main() {
 
 	flag.StringVar(&bindingName, "binding-name", "", "binding name used in search")
 	flag.StringVar(&searchTerm, "search-term", "", "fuzzy search term, used if binding name not found")
-	flag.StringVar(&sessionDriver, "session-driver", "", "session handler to configure: redis or memcached")
+	flag.StringVar(&sessionDriver, "session-driver", "", "service to configure: redis or memcached sessions, the newrelic agent, opentelemetry or the datadog tracer")
 	flag.StringVar(&platformRoot, "platform-root", "", "platform root for the CNB")
 	flag.StringVar(&appRoot, "app-root", "", "application root")
 	flag.Parse()
@@ -23,8 +24,8 @@ func main() {
 	}
 
 	sessionDriver = strings.ToLower(sessionDriver)
-	if sessionDriver != "redis" && sessionDriver != "memcached" {
-		log.Fatalln("session-driver [", sessionDriver, "] not valid. Valid options are: redis or memcached")
+	if sessionDriver != "redis" && sessionDriver != "memcached" && sessionDriver != "newrelic" && sessionDriver != "opentelemetry" && sessionDriver != "datadog" {
+		log.Fatalln("session-driver [", sessionDriver, "] not valid. Valid options are: redis, memcached, newrelic, opentelemetry or datadog")
 	}
 
 	var search features.SessionConfigurer
@@ -34,6 +35,12 @@ func main() {
 		search, err = features.NewRedisSessionSupport(platformRoot, appRoot)
 	} else if sessionDriver == "memcached" {
 		search, err = features.NewMemcachedSessionSupport(platformRoot, appRoot)
+	} else if sessionDriver == "newrelic" {
+		search, err = features.NewNewRelicSupport(platformRoot, appRoot, os.Getenv("PHP_EXTENSION_DIR"))
+	} else if sessionDriver == "opentelemetry" {
+		search, err = features.NewOpenTelemetrySupport(platformRoot, appRoot, os.Getenv("PHP_EXTENSION_DIR"))
+	} else if sessionDriver == "datadog" {
+		search, err = features.NewDatadogSupport(platformRoot, appRoot, os.Getenv("PHP_EXTENSION_DIR"))
 	}
 	if err != nil {
 		log.Fatalln("NewSessionConfigurer:", err)
@@ -41,6 +48,6 @@ func main() {
 
 	err = search.ConfigureService()
 	if err != nil {
-		log.Fatalln("Search:", err)
+		log.Fatalln("Search:", search.Redact(err.Error()))
 	}
 }
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+var (
+	composerOrPattern        = regexp.MustCompile(`\s*\|\|?\s*`)
+	composerStabilityPattern = regexp.MustCompile(`@[a-zA-Z]+`)
+	composerTildePattern     = regexp.MustCompile(`^~(\d+)\.(\d+)$`)
+)
+
+// ComposerJSON represents the parts of an application's `composer.json` used by the buildpack
+type ComposerJSON struct {
+	// Name of the package, like `vendor/project`
+	Name string `json:"name"`
+
+	// Bin lists the CLI entrypoints of the package, relative to the application root
+	Bin ComposerBin `json:"bin"`
+
+	// Require maps the required packages & platform, like `php`, to version constraints
+	Require map[string]string `json:"require"`
+}
+
+// ComposerLock represents the parts of an application's `composer.lock` used by the buildpack
+type ComposerLock struct {
+	// Platform maps the platform requirements of the root package, like `php`, to version constraints
+	Platform ComposerPlatform `json:"platform"`
+}
+
+// ComposerPlatform is the `platform` of `composer.lock`, which composer writes as an empty list when there are no requirements
+type ComposerPlatform map[string]string
+
+// UnmarshalJSON accepts an object or an empty list
+func (c *ComposerPlatform) UnmarshalJSON(data []byte) error {
+	var list []interface{}
+	if err := json.Unmarshal(data, &list); err == nil && len(list) == 0 {
+		*c = ComposerPlatform{}
+		return nil
+	}
+
+	var platform map[string]string
+	if err := json.Unmarshal(data, &platform); err != nil {
+		return fmt.Errorf("platform must map requirements to constraints: %w", err)
+	}
+
+	*c = platform
+	return nil
+}
+
+// ComposerBin is the `bin` of `composer.json`, which can either be a single path or a list of paths
+type ComposerBin []string
+
+// UnmarshalJSON accepts either a single path or a list of paths
+func (c *ComposerBin) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*c = ComposerBin{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("bin must be a path or a list of paths: %w", err)
+	}
+
+	*c = list
+	return nil
+}
+
+// LoadComposerJSON reads `composer.json` in the application root, if there is one
+func LoadComposerJSON(appRoot string) (ComposerJSON, error) {
+	contents, err := ioutil.ReadFile(filepath.Join(appRoot, "composer.json"))
+	if os.IsNotExist(err) {
+		return ComposerJSON{}, nil
+	} else if err != nil {
+		return ComposerJSON{}, err
+	}
+
+	var composerJSON ComposerJSON
+	if err := json.Unmarshal(contents, &composerJSON); err != nil {
+		return ComposerJSON{}, fmt.Errorf("unable to parse composer.json: %w", err)
+	}
+
+	return composerJSON, nil
+}
+
+// LoadComposerLock reads `composer.lock` in the application root, if there is one
+func LoadComposerLock(appRoot string) (ComposerLock, error) {
+	contents, err := ioutil.ReadFile(filepath.Join(appRoot, "composer.lock"))
+	if os.IsNotExist(err) {
+		return ComposerLock{}, nil
+	} else if err != nil {
+		return ComposerLock{}, err
+	}
+
+	var composerLock ComposerLock
+	if err := json.Unmarshal(contents, &composerLock); err != nil {
+		return ComposerLock{}, fmt.Errorf("unable to parse composer.lock: %w", err)
+	}
+
+	return composerLock, nil
+}
+
+// ComposerConstraint converts a composer version constraint, like `^7.4 | ^8.0` or `>=7.2 <8.0@stable`, to a semver constraint
+func ComposerConstraint(constraint string) (string, error) {
+	var alternatives []string
+	for _, alternative := range composerOrPattern.Split(strings.TrimSpace(constraint), -1) {
+		alternative = composerStabilityPattern.ReplaceAllString(alternative, "")
+
+		// composer separates the constraints which must all match by a space or comma, except in `<from> - <to>` ranges
+		var constraints []string
+		fields := strings.Fields(strings.ReplaceAll(alternative, ",", " "))
+		for i := 0; i < len(fields); i++ {
+			if fields[i] == "-" && len(constraints) > 0 && i+1 < len(fields) {
+				constraints[len(constraints)-1] += " - " + fields[i+1]
+				i++
+				continue
+			}
+			constraints = append(constraints, composerTilde(fields[i]))
+		}
+
+		alternatives = append(alternatives, strings.Join(constraints, ", "))
+	}
+
+	converted := strings.Join(alternatives, " || ")
+	if _, err := semver.NewConstraint(converted); err != nil {
+		return "", fmt.Errorf("unsupported constraint %q: %w", constraint, err)
+	}
+
+	return converted, nil
+}
+
+// composerTilde converts a two segment tilde, like `~7.2`, which allows any later minor version in composer but only
+// later patch versions in semver, to a range. Other constraints are returned as they are.
+func composerTilde(constraint string) string {
+	match := composerTildePattern.FindStringSubmatch(constraint)
+	if match == nil {
+		return constraint
+	}
+
+	major, err := strconv.Atoi(match[1])
+	if err != nil {
+		return constraint
+	}
+
+	return fmt.Sprintf(">=%s.%s, <%d.0", match[1], match[2], major+1)
+}
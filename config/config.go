@@ -18,9 +18,16 @@ package config
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
+	"net"
+	"net/textproto"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"text/template"
 
 	"gopkg.in/yaml.v2"
@@ -28,6 +35,7 @@ import (
 	"github.com/Masterminds/semver"
 	"github.com/cloudfoundry/libcfbuildpack/helper"
 	"github.com/cloudfoundry/libcfbuildpack/logger"
+	"github.com/paketo-buildpacks/php-web/procmgr"
 )
 
 const (
@@ -39,11 +47,136 @@ const (
 
 	// PhpWebServer is text user can specify to use PHP's built-in Web Server
 	PhpWebServer = "php-server"
+
+	// SecurityHeadersNone adds no security headers, unless they are set individually
+	SecurityHeadersNone = "none"
+
+	// SecurityHeadersRecommended adds a recommended set of security headers
+	SecurityHeadersRecommended = "recommended"
+
+	// AccessLogCommon is the Common Log Format
+	AccessLogCommon = "common"
+
+	// AccessLogCombined is the Combined Log Format, which adds the referer & user agent to AccessLogCommon
+	AccessLogCombined = "combined"
+
+	// AccessLogExtended is AccessLogCommon plus Cloud Foundry's request ID
+	AccessLogExtended = "extended"
+
+	// AccessLogJSON is a structured log, with one JSON object per request
+	AccessLogJSON = "json"
+
+	// IniProfileProduction is the buildpack's php.ini, based on PHP's php.ini-production
+	IniProfileProduction = "production"
+
+	// IniProfileDevelopment displays errors, enables assertions & picks up changed scripts on each request
+	IniProfileDevelopment = "development"
+
+	// IniProfileHardened is IniProfileProduction which also limits file access to the app & disables running commands
+	IniProfileHardened = "hardened"
+
+	// Redacted is displayed in place of secrets, like credentials & license keys, in log output
+	Redacted = "[REDACTED]"
+
+	// DefaultMaxRequestBody is the largest request body accepted by default, PHP's default `post_max_size`
+	DefaultMaxRequestBody = "8M"
+
+	// httpdMaxRequestBody is the largest value of httpd's `LimitRequestBody`, 2GB
+	httpdMaxRequestBody = 2147483647
+
+	// maxSize is the largest size accepted by parseSize, 1TB
+	maxSize = 1 << 40
+
+	// DefaultRequestTimeout is the `max_execution_time` of PHP by default, in seconds
+	DefaultRequestTimeout = 30
+
+	// FpmTimeoutMargin is the time php-fpm gives a request past the request timeout, before killing the worker, as
+	// `max_execution_time` does not count the time spent waiting, e.g. on a database
+	FpmTimeoutMargin = 5
+
+	// WebServerTimeoutMargin is the time the web server waits on php-fpm past the request timeout, so that the
+	// request is ended by PHP or php-fpm, rather than a gateway timeout
+	WebServerTimeoutMargin = 10
 )
 
 var (
+	// secretWords mark a setting or credential name as a secret, wherever they appear in it
+	secretWords = map[string]bool{"password": true, "passwd": true, "secret": true, "token": true, "license": true}
+
+	// secretNames are secrets when they are the whole name, like the `headers` credential of an OpenTelemetry service
+	secretNames = map[string]bool{"key": true, "headers": true}
+
+	// secretKeyQualifiers mark a name as a secret when they come before `key`, as in `api_key` or `access-key`
+	secretKeyQualifiers = map[string]bool{"api": true, "access": true, "auth": true, "private": true, "sasl": true}
+
+	// secretWordBoundary finds the case changes of camelCase names, like `licenseKey`
+	secretWordBoundary = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+
 	// DefaultCliScripts is the script used when one is not provided in buildpack.yml
 	DefaultCliScripts = []string{"app.php", "main.php", "run.php", "start.php"}
+
+	// DefaultTrustedProxies are the private network ranges (RFC 1918) trusted to set client IP & protocol headers
+	DefaultTrustedProxies = []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}
+
+	// RecommendedSecurityHeaders are the headers set by the `recommended` security headers profile
+	RecommendedSecurityHeaders = map[string]string{
+		"Strict-Transport-Security": "max-age=31536000",
+		"X-Content-Type-Options":    "nosniff",
+		"X-Frame-Options":           "SAMEORIGIN",
+		"Referrer-Policy":           "strict-origin-when-cross-origin",
+	}
+
+	// WebServers are the supported values of `php.webserver`
+	WebServers = []string{PhpWebServer, ApacheHttpd, Nginx}
+
+	// ReservedWorkerNames are the processes run by procmgr, which workers can't replace
+	ReservedWorkerNames = []string{"web", Nginx, ApacheHttpd, "php-fpm", "scheduler"}
+
+	// IniProfiles are the supported values of `php.ini_profile`
+	IniProfiles = []string{IniProfileProduction, IniProfileDevelopment, IniProfileHardened}
+
+	// HardenedDisableFunctions are the functions disabled by the `hardened` php.ini profile, which run commands
+	HardenedDisableFunctions = []string{"exec", "passthru", "shell_exec", "system", "proc_open", "popen", "pcntl_exec"}
+
+	// AccessLogFormats are the supported access log formats
+	AccessLogFormats = []string{AccessLogCommon, AccessLogCombined, AccessLogExtended, AccessLogJSON}
+
+	// DefaultCacheTTL is the browser cache lifetime of static files, by file extension
+	DefaultCacheTTL = map[string]string{"ico": "max", "css": "max", "js": "max", "gif": "max", "jpeg": "max", "jpg": "max", "png": "max"}
+
+	// DefaultGzipTypes are the MIME types compressed by the web server
+	DefaultGzipTypes = []string{
+		"text/html", "text/plain", "text/xml", "text/css", "text/javascript",
+		"application/javascript", "application/json", "application/xml", "application/wasm",
+		"image/svg+xml", "font/ttf", "font/otf",
+	}
+
+	// StaticAssetTypes maps the extensions of compressible static files to their MIME type
+	StaticAssetTypes = map[string]string{
+		"css":  "text/css",
+		"htm":  "text/html",
+		"html": "text/html",
+		"js":   "application/javascript",
+		"json": "application/json",
+		"map":  "application/json",
+		"mjs":  "application/javascript",
+		"otf":  "font/otf",
+		"svg":  "image/svg+xml",
+		"ttf":  "font/ttf",
+		"txt":  "text/plain",
+		"wasm": "application/wasm",
+		"xml":  "text/xml",
+	}
+
+	headerNamePattern    = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+	extensionPattern     = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+	cacheTTLPattern      = regexp.MustCompile(`^(max|off|([0-9]+)([smhdwy]?))$`)
+	mimeTypePattern      = regexp.MustCompile(`^[a-z0-9.+-]+/[a-z0-9.+-]+$`)
+	processTypePattern   = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+	iniDirectivePattern  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+	iniExpressionPattern = regexp.MustCompile(`^[A-Z0-9_ |&~^!()]+$`)
+	iniValueEscaper      = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`)
+	sizePattern          = regexp.MustCompile(`^([1-9][0-9]*)([KkMmGg]?)$`)
 )
 
 // ProcessTemplateToFile writes out a specific template to the given file name
@@ -69,6 +202,38 @@ type HttpdConfig struct {
 	AppRoot              string
 	WebDirectory         string
 	FpmSocket            string
+	Proxy                Proxy
+	SecurityHeaders      []Header
+	AccessLogFormat      string
+	RequestID            RequestID
+	LogTraceIDs          bool
+	StaticAssets         StaticAssets
+	MaxRequestBody       string
+	RequestTimeout       int
+}
+
+// ProxyTimeout is the time httpd waits on php-fpm & the client, in seconds
+func (h HttpdConfig) ProxyTimeout() int {
+	return requestTimeout(h.RequestTimeout) + WebServerTimeoutMargin
+}
+
+// LimitRequestBody is the largest request body in bytes, httpd does not accept sizes with a unit or above 2GB
+func (h HttpdConfig) LimitRequestBody() int64 {
+	bytes, _ := parseSize(maxRequestBody(h.MaxRequestBody))
+	if bytes > httpdMaxRequestBody {
+		return httpdMaxRequestBody
+	}
+	return bytes
+}
+
+// ClientIPHeaderFormat is the httpd log format string for the configured client IP header
+func (h HttpdConfig) ClientIPHeaderFormat() string {
+	return "%{" + h.Proxy.ClientIPHeader + "}i"
+}
+
+// RequestIDFormat is the httpd log format string for the configured request ID header
+func (h HttpdConfig) RequestIDFormat() string {
+	return "%{" + h.RequestID.Header + "}i"
 }
 
 // NginxConfig supplies values for templated nginx.conf
@@ -77,6 +242,137 @@ type NginxConfig struct {
 	AppRoot              string
 	WebDirectory         string
 	FpmSocket            string
+	Proxy                Proxy
+	SecurityHeaders      []Header
+	AccessLogFormat      string
+	RequestID            RequestID
+	LogTraceIDs          bool
+	StaticAssets         StaticAssets
+	MaxRequestBody       string
+	RequestTimeout       int
+}
+
+// FastCGIReadTimeout is the time nginx waits on a response of php-fpm, in seconds
+func (n NginxConfig) FastCGIReadTimeout() int {
+	return requestTimeout(n.RequestTimeout) + WebServerTimeoutMargin
+}
+
+// ClientMaxBodySize is the largest request body, for `client_max_body_size`
+func (n NginxConfig) ClientMaxBodySize() string {
+	return maxRequestBody(n.MaxRequestBody)
+}
+
+// ClientIPHeaderVariable is the nginx variable holding the configured client IP header
+func (n NginxConfig) ClientIPHeaderVariable() string {
+	return nginxHeaderVariable(n.Proxy.ClientIPHeader)
+}
+
+// GzipTypes are the MIME types for `gzip_types`, which always includes text/html
+func (n NginxConfig) GzipTypes() string {
+	var types []string
+	for _, mimeType := range n.StaticAssets.GzipTypes {
+		if mimeType != "text/html" {
+			types = append(types, mimeType)
+		}
+	}
+	return strings.Join(types, " ")
+}
+
+// RequestIDVariable is the nginx variable holding the request ID, which is generated when enabled and not sent by the client
+func (n NginxConfig) RequestIDVariable() string {
+	if n.RequestID.Enabled {
+		return "$app_request_id"
+	}
+
+	return n.RequestIDHeaderVariable()
+}
+
+// RequestIDHeaderVariable is the nginx variable holding the configured request ID header
+func (n NginxConfig) RequestIDHeaderVariable() string {
+	return nginxHeaderVariable(n.RequestID.Header)
+}
+
+// RequestIDParam is the FastCGI param under which PHP receives the request ID, the same as if it were sent by the client
+func (n NginxConfig) RequestIDParam() string {
+	return "HTTP_" + strings.ReplaceAll(strings.ToUpper(n.RequestID.Header), "-", "_")
+}
+
+// ProtoHeaderVariable is the nginx variable holding the configured protocol header
+func (n NginxConfig) ProtoHeaderVariable() string {
+	return nginxHeaderVariable(n.Proxy.ProtoHeader)
+}
+
+// ProtoVariable is the nginx variable holding the protocol the client used to reach the proxy
+func (n NginxConfig) ProtoVariable() string {
+	if n.Proxy.Forwarded {
+		return "$forwarded_proto"
+	}
+
+	return n.ProtoHeaderVariable()
+}
+
+func nginxHeaderVariable(header string) string {
+	return "$http_" + strings.ReplaceAll(strings.ToLower(header), "-", "_")
+}
+
+// routerMimeTypes are the content types of common static files, by extension, as the built-in server sends them
+var routerMimeTypes = map[string]string{
+	"css":   "text/css",
+	"gif":   "image/gif",
+	"htm":   "text/html",
+	"html":  "text/html",
+	"ico":   "image/x-icon",
+	"jpeg":  "image/jpeg",
+	"jpg":   "image/jpeg",
+	"js":    "application/javascript",
+	"json":  "application/json",
+	"map":   "application/json",
+	"pdf":   "application/pdf",
+	"png":   "image/png",
+	"svg":   "image/svg+xml",
+	"txt":   "text/plain",
+	"wasm":  "application/wasm",
+	"webp":  "image/webp",
+	"woff":  "font/woff",
+	"woff2": "font/woff2",
+	"xml":   "text/xml",
+}
+
+// PhpRouterConfig supplies values for the templated router script of PHP's built-in Web Server
+type PhpRouterConfig struct {
+	SecurityHeaders []Header
+	FrontController string
+	Router          string
+}
+
+// MimeTypes are the content types of static files, which the router serves itself when it sets security headers
+func (p PhpRouterConfig) MimeTypes() map[string]string {
+	return routerMimeTypes
+}
+
+// FrontControllerString is the front controller path, relative to the document root, as a single quoted PHP string
+func (p PhpRouterConfig) FrontControllerString() string {
+	return phpString("/" + strings.TrimPrefix(filepath.ToSlash(p.FrontController), "/"))
+}
+
+// RouterString is the path of the user supplied router script as a single quoted PHP string
+func (p PhpRouterConfig) RouterString() string {
+	return phpString(p.Router)
+}
+
+func phpString(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// Header is a HTTP response header added by the web server
+type Header struct {
+	Name  string
+	Value string
+}
+
+// PhpString is the header formatted as a single quoted PHP string, for use with `header()`
+func (h Header) PhpString() string {
+	return phpString(h.Name + ": " + h.Value)
 }
 
 // PhpIniConfig supplies values for templated php.ini
@@ -87,112 +383,620 @@ type PhpIniConfig struct {
 	PhpAPI         string
 	Extensions     []string
 	ZendExtensions []string
+
+	// Profile is one of IniProfiles, defaults to IniProfileProduction
+	Profile string
+
+	// ConfigDirectory holds the configuration written by the buildpack, like the router of PHP's built-in Web Server
+	ConfigDirectory string
+
+	// MaxRequestBody sets both `post_max_size` & `upload_max_filesize`, defaults to DefaultMaxRequestBody
+	MaxRequestBody string
+
+	// RequestTimeout is the `max_execution_time`, defaults to DefaultRequestTimeout
+	RequestTimeout int
+
+	// LiveReload checks for changed scripts on every request
+	LiveReload bool
+
+	// TempDirectory is the `TMPDIR` of the build, defaults to /tmp
+	TempDirectory string
+}
+
+// RevalidateScripts checks if OPcache looks for changed scripts on every request
+func (p PhpIniConfig) RevalidateScripts() bool {
+	return p.Development() || p.LiveReload
+}
+
+// MaxExecutionTime is the `max_execution_time` of php.ini, in seconds
+func (p PhpIniConfig) MaxExecutionTime() int {
+	return requestTimeout(p.RequestTimeout)
+}
+
+// PostMaxSize is the `post_max_size` & `upload_max_filesize` of php.ini, an upload of that size can't be accepted as
+// the rest of the multipart body counts towards `post_max_size`
+func (p PhpIniConfig) PostMaxSize() string {
+	return maxRequestBody(p.MaxRequestBody)
+}
+
+func requestTimeout(timeout int) int {
+	if timeout == 0 {
+		return DefaultRequestTimeout
+	}
+	return timeout
+}
+
+func maxRequestBody(size string) string {
+	if size == "" {
+		return DefaultMaxRequestBody
+	}
+	return size
+}
+
+// Development checks if the `development` profile is selected
+func (p PhpIniConfig) Development() bool {
+	return p.Profile == IniProfileDevelopment
+}
+
+// Hardened checks if the `hardened` profile is selected
+func (p PhpIniConfig) Hardened() bool {
+	return p.Profile == IniProfileHardened
+}
+
+// OpenBasedir lists the directories PHP may access with the `hardened` profile: the app, its libraries, PHP's own
+// libraries, the buildpack's configuration & the temporary directory, used for uploads & file based sessions
+func (p PhpIniConfig) OpenBasedir() string {
+	directories := []string{
+		p.AppRoot,
+		filepath.Join(p.AppRoot, p.LibDirectory),
+		filepath.Join(p.PhpHome, "lib", "php"),
+		p.ConfigDirectory,
+		p.TempDir(),
+	}
+	return strings.Join(directories, string(os.PathListSeparator))
+}
+
+// TempDir is the `sys_temp_dir` & `upload_tmp_dir` of the `hardened` profile, which are set to the directory allowed by
+// OpenBasedir so that a different `TMPDIR` at launch can't move temporary files outside of it
+func (p PhpIniConfig) TempDir() string {
+	if p.TempDirectory == "" {
+		return "/tmp"
+	}
+	return filepath.Clean(p.TempDirectory)
+}
+
+// DisableFunctions is the `disable_functions` list of the `hardened` profile
+func (p PhpIniConfig) DisableFunctions() string {
+	return strings.Join(HardenedDisableFunctions, ",")
 }
 
 // PhpFpmConfig supplies values for templated php-fpm.conf
 type PhpFpmConfig struct {
-	PhpHome string
-	PhpAPI  string
-	Include string
-	Listen  string
+	PhpHome        string
+	PhpAPI         string
+	Include        string
+	Listen         string
+	RequestTimeout int
+}
+
+// RequestTerminateTimeout is the time after which php-fpm kills a worker serving a request, in seconds
+func (p PhpFpmConfig) RequestTerminateTimeout() int {
+	return requestTimeout(p.RequestTimeout) + FpmTimeoutMargin
 }
 
 // BuildpackYAML represents user specified config options through `buildpack.yml`
 type BuildpackYAML struct {
 	Config Config `yaml:"php"`
+
+	// setKeys are the dotted paths of the keys explicitly set in `buildpack.yml`, like `php.webserver`
+	setKeys map[string]bool
+
+	// unknownKeys are the keys under `php` which are not configuration options
+	unknownKeys []UnknownKey
+}
+
+// IsSet checks if key, a dotted path like `php.webserver`, was explicitly set in `buildpack.yml`, even to its default value
+func (b BuildpackYAML) IsSet(key string) bool {
+	return b.setKeys[key]
+}
+
+// UnknownKeys are the keys under `php` in `buildpack.yml` which are not configuration options, sorted by path
+func (b BuildpackYAML) UnknownKeys() []UnknownKey {
+	return b.unknownKeys
 }
 
 // Config represents PHP specific configuration options for BuildpackYAML
 type Config struct {
-	Version             string    `yaml:"version"`
-	WebServer           string    `yaml:"webserver"`
-	WebDirectory        string    `yaml:"webdirectory"`
-	LibDirectory        string    `yaml:"libdirectory"`
-	Script              string    `yaml:"script"`
-	ServerAdmin         string    `yaml:"serveradmin"`
-	EnableHTTPSRedirect bool      `yaml:"enable_https_redirect"`
-	Redis               Redis     `yaml:"redis"`
-	Memcached           Memcached `yaml:"memcached"`
+	Version             string          `yaml:"version"`
+	WebServer           string          `yaml:"webserver"`
+	WebDirectory        string          `yaml:"webdirectory"`
+	LibDirectory        string          `yaml:"libdirectory"`
+	Script              string          `yaml:"script"`
+	ScriptArgs          []string        `yaml:"script_args,omitempty"`
+	ServerAdmin         string          `yaml:"serveradmin"`
+	EnableHTTPSRedirect bool            `yaml:"enable_https_redirect"`
+	Redis               Redis           `yaml:"redis"`
+	Memcached           Memcached       `yaml:"memcached"`
+	Proxy               Proxy           `yaml:"proxy"`
+	SecurityHeaders     SecurityHeaders `yaml:"security_headers"`
+	AccessLogFormat     string          `yaml:"access_log_format,omitempty"`
+	RequestID           RequestID       `yaml:"request_id"`
+	StaticAssets        StaticAssets    `yaml:"static_assets"`
+	PhpServer           PhpServer       `yaml:"php_server"`
+	Processes           Processes       `yaml:"processes,omitempty"`
+	Workers             Workers         `yaml:"workers,omitempty"`
+	Schedule            []string        `yaml:"schedule,omitempty"`
+	Ini                 IniDirectives   `yaml:"ini,omitempty"`
+	IniProfile          string          `yaml:"ini_profile,omitempty"`
+	MaxRequestBody      string          `yaml:"max_request_body,omitempty"`
+	RequestTimeout      int             `yaml:"request_timeout,omitempty"`
+	LiveReload          bool            `yaml:"live_reload,omitempty"`
+	OpenTelemetry       OpenTelemetry   `yaml:"opentelemetry"`
 }
 
-// Redis represents PHP Redis specific configuration options for `buildpack.yml`
-type Redis struct {
-	SessionStoreServiceName string `yaml:"session_store_service_name"`
+// String formats the configuration for logging, with the values of secret ini directives masked
+func (b BuildpackYAML) String() string {
+	type plain BuildpackYAML // drops the String method, to avoid recursion
+
+	masked := plain{Config: b.Config}
+	if len(b.Config.Ini) > 0 {
+		masked.Config.Ini = IniDirectives{}
+		for name, value := range b.Config.Ini {
+			if IsSecret(name) {
+				value = Redacted
+			}
+			masked.Config.Ini[name] = value
+		}
+	}
+
+	return fmt.Sprintf("%v", masked)
 }
 
-// Memcached represents PHP Memcached specific configuration options for `buildpack.yml`
-type Memcached struct {
-	SessionStoreServiceName string `yaml:"session_store_service_name"`
+// IsSecret determines if a setting or credential named name holds a secret, like a password or license key. The name
+// is split into words at `.`, `_`, `-` & case changes, so that `newrelic.license` & `licenseKey` match, but `keys_zone`
+// & `cache_key` don't.
+func IsSecret(name string) bool {
+	words := strings.FieldsFunc(strings.ToLower(secretWordBoundary.ReplaceAllString(name, "${1}_${2}")), func(r rune) bool {
+		return r == '.' || r == '_' || r == '-'
+	})
+
+	if len(words) == 1 && secretNames[words[0]] {
+		return true
+	}
+
+	for i, word := range words {
+		if secretWords[word] || (word == "key" && i > 0 && secretKeyQualifiers[words[i-1]]) {
+			return true
+		}
+	}
+
+	return false
 }
 
-func WarnBuildpackYAML(logger logger.Logger, version, appRoot string) error {
-	buildpackYAML, configFile := BuildpackYAML{}, filepath.Join(appRoot, "buildpack.yml")
-	var (
-		exists bool
-		err    error
-	)
-	if exists, err = helper.FileExists(configFile); err != nil {
-		return err
+// Proxy represents options for requests arriving through a reverse proxy, load balancer or CDN
+type Proxy struct {
+	// TrustedRanges are the addresses or CIDR ranges of proxies allowed to set the client IP
+	TrustedRanges []string `yaml:"trusted_ranges,omitempty"`
+
+	// ClientIPHeader is the header a trusted proxy uses to pass the client's IP address
+	ClientIPHeader string `yaml:"client_ip_header,omitempty"`
+
+	// ProtoHeader is the header a proxy uses to pass the protocol the client connected with
+	ProtoHeader string `yaml:"proto_header,omitempty"`
+
+	// Forwarded enables reading the protocol from the RFC 7239 `Forwarded` header, ahead of ProtoHeader
+	Forwarded bool `yaml:"forwarded,omitempty"`
+}
+
+// Validate checks that the proxy options can be safely written to web server configuration
+func (p Proxy) Validate() error {
+	for _, trusted := range p.TrustedRanges {
+		if _, _, err := net.ParseCIDR(trusted); err != nil && net.ParseIP(trusted) == nil {
+			return fmt.Errorf("invalid php.proxy.trusted_ranges entry %q, must be an IP address or CIDR range", trusted)
+		}
 	}
 
-	if !exists {
-		return nil
+	if !headerNamePattern.MatchString(p.ClientIPHeader) {
+		return fmt.Errorf("invalid php.proxy.client_ip_header %q, must be a header name", p.ClientIPHeader)
 	}
 
-	file, err := os.Open(configFile)
-	if err != nil {
-		return err
+	if !headerNamePattern.MatchString(p.ProtoHeader) {
+		return fmt.Errorf("invalid php.proxy.proto_header %q, must be a header name", p.ProtoHeader)
 	}
-	defer file.Close()
 
-	contents, err := ioutil.ReadAll(file)
-	if err != nil {
-		return err
+	return nil
+}
+
+// RequestID represents options for tagging each request with an ID, to correlate web server & application logs
+type RequestID struct {
+	// Enabled generates an ID for requests without one, passes it to PHP & logs it in the extended & json formats
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// Header is the request header used to receive the ID from the edge & pass it to PHP
+	Header string `yaml:"header,omitempty"`
+}
+
+// OpenTelemetry represents options for tracing requests in the app
+type OpenTelemetry struct {
+	// Enabled loads the `opentelemetry` extension when the app is launched
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// ServiceName is the `service.name` resource attribute, defaulting to the package name in composer.json
+	ServiceName string `yaml:"service_name,omitempty"`
+}
+
+// StaticAssets represents the caching & compression policy for static files served by the web server
+type StaticAssets struct {
+	// CacheTTL is the browser cache lifetime by file extension, e.g. `30d`, `1y` or `max`, `off` disables caching
+	CacheTTL map[string]string `yaml:"cache_ttl,omitempty"`
+
+	// GzipTypes are the MIME types compressed on the fly
+	GzipTypes []string `yaml:"gzip_types,omitempty"`
+
+	// Precompressed serves `.gz` & `.br` sidecar files, when they exist, instead of compressing on the fly.
+	// `.gz` sidecars are also written at build time.
+	Precompressed bool `yaml:"precompressed,omitempty"`
+
+	// PrecompressMinSize is the size in bytes below which no `.gz` sidecar is written at build time
+	PrecompressMinSize int `yaml:"precompress_min_size,omitempty"`
+}
+
+// AssetType is the MIME type of static files with the given extension
+type AssetType struct {
+	Extension string
+	MimeType  string
+}
+
+// CacheRule is the browser cache lifetime of static files with the given extensions
+type CacheRule struct {
+	Extensions []string
+	TTL        string
+}
+
+// Pattern is a regular expression alternation matching the extensions
+func (c CacheRule) Pattern() string {
+	return strings.Join(c.Extensions, "|")
+}
+
+// MaxAge is the TTL in seconds, `max` is ten years like nginx's `expires max`
+func (c CacheRule) MaxAge() int {
+	matches := cacheTTLPattern.FindStringSubmatch(c.TTL)
+	if matches == nil || matches[1] == "off" {
+		return 0
 	}
 
-	err = yaml.Unmarshal(contents, &buildpackYAML)
-	if err != nil {
-		return err
+	if matches[1] == "max" {
+		return 315360000
 	}
 
-	fieldMapping := map[string]string{}
-	if buildpackYAML.Config.Version != "" {
-		fieldMapping["php.version"] = "BP_PHP_VERSION"
+	value, _ := strconv.Atoi(matches[2])
+	units := map[string]int{"": 1, "s": 1, "m": 60, "h": 3600, "d": 86400, "w": 604800, "y": 31536000}
+	return value * units[matches[3]]
+}
+
+// CacheRules groups the extensions by TTL, skipping those which are `off`
+func (s StaticAssets) CacheRules() []CacheRule {
+	byTTL := map[string][]string{}
+	for extension, ttl := range s.CacheTTL {
+		if ttl != "off" {
+			byTTL[ttl] = append(byTTL[ttl], strings.ToLower(extension))
+		}
 	}
-	if buildpackYAML.Config.LibDirectory != "" {
-		fieldMapping["php.libdirectory"] = "BP_PHP_LIB_DIR"
+
+	var rules []CacheRule
+	for ttl, extensions := range byTTL {
+		sort.Strings(extensions)
+		rules = append(rules, CacheRule{Extensions: extensions, TTL: ttl})
 	}
-	if buildpackYAML.Config.WebDirectory != "" {
-		fieldMapping["php.webdirectory"] = "BP_PHP_WEB_DIR"
+
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].TTL < rules[j].TTL
+	})
+
+	return rules
+}
+
+// GzipTypeList is the space separated list of GzipTypes
+func (s StaticAssets) GzipTypeList() string {
+	return strings.Join(s.GzipTypes, " ")
+}
+
+// CompressibleExtensions are the known static file extensions with one of the GzipTypes, sorted
+func (s StaticAssets) CompressibleExtensions() []string {
+	var extensions []string
+	for extension, mimeType := range StaticAssetTypes {
+		if contains(s.GzipTypes, mimeType) {
+			extensions = append(extensions, extension)
+		}
 	}
-	if buildpackYAML.Config.WebServer != "" {
-		fieldMapping["php.webserver"] = "BP_PHP_SERVER"
+
+	sort.Strings(extensions)
+	return extensions
+}
+
+// CompressiblePattern is a regular expression alternation matching the CompressibleExtensions
+func (s StaticAssets) CompressiblePattern() string {
+	return strings.Join(s.CompressibleExtensions(), "|")
+}
+
+// CompressibleTypes are the CompressibleExtensions with their MIME type
+func (s StaticAssets) CompressibleTypes() []AssetType {
+	var types []AssetType
+	for _, extension := range s.CompressibleExtensions() {
+		types = append(types, AssetType{Extension: extension, MimeType: StaticAssetTypes[extension]})
+	}
+	return types
+}
+
+// Validate checks that the static asset policy can be safely written to web server configuration
+func (s StaticAssets) Validate() error {
+	for extension, ttl := range s.CacheTTL {
+		if !extensionPattern.MatchString(extension) {
+			return fmt.Errorf("invalid php.static_assets.cache_ttl extension %q, must be alphanumeric", extension)
+		}
+
+		if !cacheTTLPattern.MatchString(ttl) {
+			return fmt.Errorf("invalid php.static_assets.cache_ttl value %q for %q, must be `max`, `off` or a number with an optional unit of s, m, h, d, w or y", ttl, extension)
+		}
+	}
+
+	if s.PrecompressMinSize < 0 {
+		return fmt.Errorf("invalid php.static_assets.precompress_min_size %d, must not be negative", s.PrecompressMinSize)
+	}
+
+	for _, mimeType := range s.GzipTypes {
+		if !mimeTypePattern.MatchString(mimeType) {
+			return fmt.Errorf("invalid php.static_assets.gzip_types entry %q, must be a MIME type", mimeType)
+		}
+	}
+
+	return nil
+}
+
+// Processes are additional launch processes declared by the user, by process type, e.g. `worker`, `scheduler` or `migrate`
+type Processes map[string]string
+
+// Validate checks that the process types are valid & don't replace the generated `web` process
+func (p Processes) Validate() error {
+	for processType, command := range p {
+		if !processTypePattern.MatchString(processType) {
+			return fmt.Errorf("invalid php.processes type %q, must only contain letters, numbers, '.', '_' and '-'", processType)
+		}
+
+		if processType == "web" {
+			return fmt.Errorf("invalid php.processes type %q, the web process is generated by the buildpack", processType)
+		}
+
+		if strings.TrimSpace(command) == "" {
+			return fmt.Errorf("invalid php.processes command for %q, must not be empty", processType)
+		}
+	}
+
+	return nil
+}
+
+// IniDirectives are php.ini settings by directive, e.g. `memory_limit` or `date.timezone`, read after the buildpack's php.ini
+type IniDirectives map[string]string
+
+// Validate checks that the directives can be written to an ini file
+func (i IniDirectives) Validate() error {
+	for name, value := range i {
+		if !iniDirectivePattern.MatchString(name) {
+			return fmt.Errorf("invalid php.ini directive %q, must only contain letters, numbers, '.' and '_'", name)
+		}
+
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("invalid php.ini value for %q, must be a single line", name)
+		}
+
+		// the web server & php-fpm limits are derived from php.request_timeout & php.max_request_body, so they must
+		// stay the only source
+		switch name {
+		case "max_execution_time":
+			return fmt.Errorf("invalid php.ini directive %q, set php.request_timeout instead", name)
+		case "post_max_size", "upload_max_filesize":
+			return fmt.Errorf("invalid php.ini directive %q, set php.max_request_body instead", name)
+		}
+	}
+
+	return nil
+}
+
+// Quoted are the directives with their values as written to an ini file, see QuoteIniValue
+func (i IniDirectives) Quoted() map[string]string {
+	quoted := map[string]string{}
+	for name, value := range i {
+		quoted[name] = QuoteIniValue(value)
+	}
+	return quoted
+}
+
+// QuoteIniValue quotes value for an ini file, escaping the `\`, `"` & `$` characters PHP reads specially inside double
+// quotes. Values made of constants, numbers & bitwise operators, like `E_ALL & ~E_DEPRECATED`, are left unquoted, as
+// PHP only evaluates them outside quotes.
+func QuoteIniValue(value string) string {
+	if iniExpressionPattern.MatchString(value) {
+		return value
+	}
+
+	return `"` + iniValueEscaper.Replace(value) + `"`
+}
+
+// Workers are long-running processes, like queue workers, supervised by procmgr next to php-fpm, by name
+type Workers map[string]Worker
+
+// Worker is a long-running process, restarted when it exits
+type Worker struct {
+	Command string `yaml:"command"`
+
+	// Replicas is the number of copies to run, defaults to one
+	Replicas int `yaml:"replicas,omitempty"`
+}
+
+// Validate checks that the worker names don't clash with the processes run by procmgr & the commands are set
+func (w Workers) Validate() error {
+	for name, worker := range w {
+		if !processTypePattern.MatchString(name) {
+			return fmt.Errorf("invalid php.workers name %q, must only contain letters, numbers, '.', '_' and '-'", name)
+		}
+
+		if contains(ReservedWorkerNames, name) {
+			return fmt.Errorf("invalid php.workers name %q, must not be one of: %s", name, strings.Join(ReservedWorkerNames, ", "))
+		}
+
+		if strings.TrimSpace(worker.Command) == "" {
+			return fmt.Errorf("invalid php.workers command for %q, must not be empty", name)
+		}
+
+		if worker.Replicas < 0 {
+			return fmt.Errorf("invalid php.workers replicas for %q, must not be negative", name)
+		}
 	}
-	if buildpackYAML.Config.ServerAdmin != "" {
-		fieldMapping["php.serveradmin"] = "BP_PHP_SERVER_ADMIN"
+
+	return nil
+}
+
+// PhpServer represents the configuration of PHP's built-in Web Server
+type PhpServer struct {
+	// FrontController handles requests which don't match a file, relative to the web directory
+	FrontController string `yaml:"front_controller,omitempty"`
+
+	// Router is a router script supplied by the application, relative to the application root, used in place of front controller routing
+	Router string `yaml:"router,omitempty"`
+
+	// Workers is the number of worker processes, sets `PHP_CLI_SERVER_WORKERS` which requires PHP 7.4+
+	Workers int `yaml:"workers,omitempty"`
+}
+
+// Validate checks that the paths stay within the application & the number of workers is sensible
+func (p PhpServer) Validate() error {
+	if !isRelativePath(p.FrontController) {
+		return fmt.Errorf("invalid php.php_server.front_controller %q, must be a relative path within the web directory", p.FrontController)
+	}
+
+	if p.Router != "" && !isRelativePath(p.Router) {
+		return fmt.Errorf("invalid php.php_server.router %q, must be a relative path within the application", p.Router)
+	}
+
+	if p.Workers < 0 {
+		return fmt.Errorf("invalid php.php_server.workers %d, must not be negative", p.Workers)
 	}
-	if buildpackYAML.Config.Redis.SessionStoreServiceName != "" {
-		fieldMapping["php.redis.session_store_service_name"] = "use a service binding"
+
+	return nil
+}
+
+// isRelativePath checks that path is non-empty, relative & doesn't escape the directory it's relative to
+func isRelativePath(path string) bool {
+	cleaned := filepath.Clean(path)
+	return path != "" && !filepath.IsAbs(path) && cleaned != ".." && !strings.HasPrefix(cleaned, "../")
+}
+
+// SecurityHeaders represents the security related response headers added by the web server
+type SecurityHeaders struct {
+	// Profile is the base set of headers, either `none` or `recommended`
+	Profile string `yaml:"profile,omitempty"`
+
+	// Headers override the profile, an empty value removes the header
+	Headers map[string]string `yaml:"headers,omitempty"`
+}
+
+// Resolve merges the profile & individually set headers, sorted by name
+func (s SecurityHeaders) Resolve() []Header {
+	merged := map[string]string{}
+	if s.Profile == SecurityHeadersRecommended {
+		for name, value := range RecommendedSecurityHeaders {
+			merged[textproto.CanonicalMIMEHeaderKey(name)] = value
+		}
+	}
+
+	for name, value := range s.Headers {
+		merged[textproto.CanonicalMIMEHeaderKey(name)] = value
+	}
+
+	var headers []Header
+	for name, value := range merged {
+		if value != "" {
+			headers = append(headers, Header{Name: name, Value: value})
+		}
+	}
+
+	sort.Slice(headers, func(i, j int) bool {
+		return headers[i].Name < headers[j].Name
+	})
+
+	return headers
+}
+
+// Validate checks that the security headers can be safely written to web server configuration
+func (s SecurityHeaders) Validate() error {
+	if s.Profile != "" && s.Profile != SecurityHeadersNone && s.Profile != SecurityHeadersRecommended {
+		return fmt.Errorf("invalid php.security_headers.profile %q, must be one of: %s, %s", s.Profile, SecurityHeadersNone, SecurityHeadersRecommended)
+	}
+
+	for name, value := range s.Headers {
+		if !headerNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid php.security_headers.headers name %q, must be a header name", name)
+		}
+
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("invalid php.security_headers.headers value for %q, must not contain line breaks", name)
+		}
 	}
-	if buildpackYAML.Config.Memcached.SessionStoreServiceName != "" {
-		fieldMapping["php.memcached.session_store_service_name"] = "use a service binding"
+
+	return nil
+}
+
+// Redis represents PHP Redis specific configuration options for `buildpack.yml`
+type Redis struct {
+	SessionStoreServiceName string `yaml:"session_store_service_name"`
+}
+
+// Memcached represents PHP Memcached specific configuration options for `buildpack.yml`
+type Memcached struct {
+	SessionStoreServiceName string `yaml:"session_store_service_name"`
+}
+
+// deprecatedKeys are the `buildpack.yml` keys which will be replaced, in the order they're reported, with their replacement
+var deprecatedKeys = [][2]string{
+	{"php.version", "BP_PHP_VERSION"},
+	{"php.libdirectory", "BP_PHP_LIB_DIR"},
+	{"php.webdirectory", "BP_PHP_WEB_DIR"},
+	{"php.webserver", "BP_PHP_SERVER"},
+	{"php.serveradmin", "BP_PHP_SERVER_ADMIN"},
+	{"php.redis.session_store_service_name", "use a service binding"},
+	{"php.memcached.session_store_service_name", "use a service binding"},
+	{"php.script", "use a Procfile"},
+	{"php.enable_https_redirect", "BP_PHP_ENABLE_HTTPS_REDIRECT"},
+}
+
+// WarnBuildpackYAML warns about the unknown & deprecated keys explicitly set in the loaded `buildpack.yml`
+func WarnBuildpackYAML(logger logger.Logger, version string, buildpackYAML BuildpackYAML) {
+	if len(buildpackYAML.unknownKeys) > 0 {
+		for _, unknownKey := range buildpackYAML.unknownKeys {
+			logger.BodyWarning("WARNING: buildpack.yml has an %s", unknownKey)
+		}
+		logger.BodyWarning("Unknown keys are ignored, set %s=true to fail the build instead.", StrictConfigEnv)
 	}
-	if buildpackYAML.Config.Script != "" {
-		fieldMapping["php.script"] = "use a Procfile"
+
+	var replacements []string
+	for _, deprecated := range deprecatedKeys {
+		if buildpackYAML.IsSet(deprecated[0]) {
+			replacements = append(replacements, fmt.Sprintf("%s -> %s", deprecated[0], deprecated[1]))
+		}
 	}
-	if buildpackYAML.Config.EnableHTTPSRedirect {
-		fieldMapping["php.enable_https_redirect"] = "BP_PHP_ENABLE_HTTPS_REDIRECT"
+
+	// options without a replacement, like php.ini, are not deprecated
+	if len(replacements) == 0 {
+		return
 	}
 
 	nextMajorVersion := semver.MustParse(version).IncMajor()
 	logger.BodyWarning("WARNING: Setting PHP configurations through buildpack.yml will be deprecated soon in buildpack v%s.", nextMajorVersion.String())
 	logger.BodyWarning("Buildpack.yml values will be replaced by environment variables in the next major version:")
 
-	for field, envVar := range fieldMapping {
-		logger.BodyWarning("  %s -> %s", field, envVar)
+	for _, replacement := range replacements {
+		logger.BodyWarning("  %s", replacement)
 	}
-
-	return nil
 }
 
 // LoadBuildpackYAML reads `buildpack.yml` and PHP specific config options in it
@@ -206,6 +1010,22 @@ func LoadBuildpackYAML(appRoot string) (BuildpackYAML, error) {
 	buildpackYAML.Config.Redis.SessionStoreServiceName = "redis-sessions"
 	buildpackYAML.Config.Memcached.SessionStoreServiceName = "memcached-sessions"
 	buildpackYAML.Config.EnableHTTPSRedirect = true
+	buildpackYAML.Config.Proxy.TrustedRanges = append([]string{}, DefaultTrustedProxies...)
+	buildpackYAML.Config.Proxy.ClientIPHeader = "X-Forwarded-For"
+	buildpackYAML.Config.Proxy.ProtoHeader = "X-Forwarded-Proto"
+	buildpackYAML.Config.SecurityHeaders.Profile = SecurityHeadersNone
+	buildpackYAML.Config.AccessLogFormat = AccessLogExtended
+	buildpackYAML.Config.RequestID.Header = "X-Request-Id"
+	buildpackYAML.Config.StaticAssets.CacheTTL = map[string]string{}
+	for extension, ttl := range DefaultCacheTTL {
+		buildpackYAML.Config.StaticAssets.CacheTTL[extension] = ttl
+	}
+	buildpackYAML.Config.StaticAssets.GzipTypes = append([]string{}, DefaultGzipTypes...)
+	buildpackYAML.Config.StaticAssets.PrecompressMinSize = 1024
+	buildpackYAML.Config.PhpServer.FrontController = "index.php"
+	buildpackYAML.Config.IniProfile = IniProfileProduction
+	buildpackYAML.Config.MaxRequestBody = DefaultMaxRequestBody
+	buildpackYAML.Config.RequestTimeout = DefaultRequestTimeout
 
 	if exists, err := helper.FileExists(configFile); err != nil {
 		return BuildpackYAML{}, err
@@ -225,13 +1045,144 @@ func LoadBuildpackYAML(appRoot string) (BuildpackYAML, error) {
 		if err != nil {
 			return BuildpackYAML{}, err
 		}
+
+		buildpackYAML.setKeys, buildpackYAML.unknownKeys, err = parseKeys(contents)
+		if err != nil {
+			return BuildpackYAML{}, err
+		}
+
+		if isStrictConfig() && len(buildpackYAML.unknownKeys) > 0 {
+			return BuildpackYAML{}, unknownKeysError(buildpackYAML.unknownKeys)
+		}
+	}
+
+	// an empty value, like `webserver: ""`, selects the default
+	if buildpackYAML.Config.WebServer == "" {
+		buildpackYAML.Config.WebServer = PhpWebServer
+	}
+	if buildpackYAML.Config.WebDirectory == "" {
+		buildpackYAML.Config.WebDirectory = "htdocs"
+	}
+	if buildpackYAML.Config.LibDirectory == "" {
+		buildpackYAML.Config.LibDirectory = "lib"
+	}
+
+	buildpackYAML.Config.WebServer = strings.ToLower(buildpackYAML.Config.WebServer)
+	if !contains(WebServers, buildpackYAML.Config.WebServer) {
+		return BuildpackYAML{}, fmt.Errorf("invalid php.webserver %q, must be one of: %s", buildpackYAML.Config.WebServer, strings.Join(WebServers, ", "))
+	}
+
+	if !isRelativePath(buildpackYAML.Config.WebDirectory) {
+		return BuildpackYAML{}, fmt.Errorf("invalid php.webdirectory %q, must be a relative path within the application", buildpackYAML.Config.WebDirectory)
+	}
+
+	if !isRelativePath(buildpackYAML.Config.LibDirectory) {
+		return BuildpackYAML{}, fmt.Errorf("invalid php.libdirectory %q, must be a relative path within the application", buildpackYAML.Config.LibDirectory)
+	}
+
+	if buildpackYAML.Config.Script != "" && !isRelativePath(buildpackYAML.Config.Script) {
+		return BuildpackYAML{}, fmt.Errorf("invalid php.script %q, must be a relative path within the application", buildpackYAML.Config.Script)
+	}
+
+	if err := buildpackYAML.Config.Proxy.Validate(); err != nil {
+		return BuildpackYAML{}, err
+	}
+
+	if err := buildpackYAML.Config.SecurityHeaders.Validate(); err != nil {
+		return BuildpackYAML{}, err
+	}
+
+	if err := buildpackYAML.Config.StaticAssets.Validate(); err != nil {
+		return BuildpackYAML{}, err
+	}
+
+	if err := buildpackYAML.Config.PhpServer.Validate(); err != nil {
+		return BuildpackYAML{}, err
+	}
+
+	if err := buildpackYAML.Config.Processes.Validate(); err != nil {
+		return BuildpackYAML{}, err
+	}
+
+	if err := buildpackYAML.Config.Workers.Validate(); err != nil {
+		return BuildpackYAML{}, err
+	}
+
+	if err := buildpackYAML.Config.Ini.Validate(); err != nil {
+		return BuildpackYAML{}, err
+	}
+
+	if buildpackYAML.Config.MaxRequestBody == "" {
+		buildpackYAML.Config.MaxRequestBody = DefaultMaxRequestBody
+	}
+	if _, ok := parseSize(buildpackYAML.Config.MaxRequestBody); !ok {
+		return BuildpackYAML{}, fmt.Errorf("invalid php.max_request_body %q, must be a number of bytes with an optional K, M or G suffix", buildpackYAML.Config.MaxRequestBody)
+	}
+
+	if buildpackYAML.Config.RequestTimeout <= 0 {
+		return BuildpackYAML{}, fmt.Errorf("invalid php.request_timeout %d, must be a positive number of seconds", buildpackYAML.Config.RequestTimeout)
+	}
+
+	if buildpackYAML.Config.IniProfile == "" {
+		buildpackYAML.Config.IniProfile = IniProfileProduction
+	}
+	if !contains(IniProfiles, buildpackYAML.Config.IniProfile) {
+		return BuildpackYAML{}, fmt.Errorf("invalid php.ini_profile %q, must be one of: %s", buildpackYAML.Config.IniProfile, strings.Join(IniProfiles, ", "))
+	}
+
+	for _, line := range buildpackYAML.Config.Schedule {
+		if _, err := procmgr.ParseScheduledJob(line); err != nil {
+			return BuildpackYAML{}, fmt.Errorf("invalid php.schedule entry: %w", err)
+		}
+	}
+
+	if !headerNamePattern.MatchString(buildpackYAML.Config.RequestID.Header) {
+		return BuildpackYAML{}, fmt.Errorf("invalid php.request_id.header %q, must be a header name", buildpackYAML.Config.RequestID.Header)
 	}
 
-	//TODO: valid WebServer
+	if !contains(AccessLogFormats, buildpackYAML.Config.AccessLogFormat) {
+		return BuildpackYAML{}, fmt.Errorf("invalid php.access_log_format %q, must be one of: %s", buildpackYAML.Config.AccessLogFormat, strings.Join(AccessLogFormats, ", "))
+	}
 
 	return buildpackYAML, nil
 }
 
+// parseSize converts a size in bytes with an optional K, M or G suffix, like `8M`, to bytes, in the way PHP & nginx do
+func parseSize(size string) (int64, bool) {
+	match := sizePattern.FindStringSubmatch(size)
+	if match == nil {
+		return 0, false
+	}
+
+	bytes, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	for _, unit := range []string{"", "K", "M", "G"} {
+		if strings.ToUpper(match[2]) == unit {
+			break
+		}
+
+		// checked before multiplying, as a large enough number would overflow
+		if bytes > maxSize/1024 {
+			return 0, false
+		}
+		bytes *= 1024
+	}
+
+	return bytes, bytes <= maxSize
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 func PickWebDir(buildpackYAML BuildpackYAML) string {
 	if buildpackYAML.Config.WebDirectory != "" {
 		return buildpackYAML.Config.WebDirectory
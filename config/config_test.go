@@ -18,10 +18,14 @@ package config
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/Masterminds/semver"
 	bp "github.com/buildpack/libbuildpack/logger"
 	"github.com/cloudfoundry/libcfbuildpack/logger"
 	"github.com/cloudfoundry/libcfbuildpack/test"
@@ -37,6 +41,12 @@ func TestUnitPhpAppConfig(t *testing.T) {
 func testPhpAppConfig(t *testing.T, when spec.G, it spec.S) {
 	var f *test.BuildFactory
 
+	defaultProxy := Proxy{
+		TrustedRanges:  DefaultTrustedProxies,
+		ClientIPHeader: "X-Forwarded-For",
+		ProtoHeader:    "X-Forwarded-Proto",
+	}
+
 	it.Before(func() {
 		RegisterTestingT(t)
 		f = test.NewBuildFactory(t)
@@ -45,10 +55,13 @@ func testPhpAppConfig(t *testing.T, when spec.G, it spec.S) {
 	when("config generation", func() {
 		it("generates an httpd.conf from the template", func() {
 			cfg := HttpdConfig{
-				AppRoot:      "/app",
-				ServerAdmin:  "test@example.org",
-				WebDirectory: "htdocs",
-				FpmSocket:    "127.0.0.1:9000",
+				AppRoot:         "/app",
+				ServerAdmin:     "test@example.org",
+				WebDirectory:    "htdocs",
+				FpmSocket:       "127.0.0.1:9000",
+				Proxy:           defaultProxy,
+				AccessLogFormat: AccessLogExtended,
+				StaticAssets:    StaticAssets{CacheTTL: DefaultCacheTTL, GzipTypes: DefaultGzipTypes},
 			}
 
 			err := ProcessTemplateToFile(HttpdConfTemplate, filepath.Join(f.Home, "httpd.conf"), cfg)
@@ -64,9 +77,10 @@ func testPhpAppConfig(t *testing.T, when spec.G, it spec.S) {
 			Expect(result).To(ContainSubstring(`<FilesMatch "^\.">`))
 			Expect(result).To(ContainSubstring(`ErrorLog "/proc/self/fd/2"`))
 			Expect(result).To(ContainSubstring(`CustomLog "/proc/self/fd/1" extended`))
-			Expect(result).To(ContainSubstring(`RemoteIpHeader x-forwarded-for`))
+			Expect(result).To(ContainSubstring(`RemoteIpHeader X-Forwarded-For`))
 			Expect(result).To(ContainSubstring(`RemoteIpInternalProxy 10.0.0.0/8 172.16.0.0/12 192.168.0.0/16`))
-			Expect(result).To(ContainSubstring(`SetEnvIf x-forwarded-proto https HTTPS=on`))
+			Expect(result).To(ContainSubstring(`SetEnvIf X-Forwarded-Proto https HTTPS=on`))
+			Expect(result).ToNot(ContainSubstring(`Forwarded proto`))
 			Expect(result).To(ContainSubstring(`RewriteRule ^ https://%{HTTP_HOST}%{REQUEST_URI} [L,R=301,NE]`))
 			Expect(result).To(ContainSubstring(`Define fcgi-listener fcgi://127.0.0.1:9000/app/htdocs`))
 			Expect(result).To(ContainSubstring(`<Proxy "${fcgi-listener}">`))
@@ -75,6 +89,9 @@ func testPhpAppConfig(t *testing.T, when spec.G, it spec.S) {
 			Expect(result).To(ContainSubstring(`SetHandler proxy:fcgi://127.0.0.1:9000`))
 			Expect(result).To(ContainSubstring(`RequestHeader unset Proxy early`))
 			Expect(result).To(ContainSubstring(`IncludeOptional "/app/.httpd.conf.d/*.conf"`))
+			Expect(result).To(ContainSubstring(`AddOutputFilterByType DEFLATE text/html text/plain text/xml text/css text/javascript application/javascript application/json`))
+			Expect(result).To(ContainSubstring("<FilesMatch \"(?i)\\.(?:css|gif|ico|jpeg|jpg|js|png)(?:\\.br|\\.gz)?$\">\n    Header set Cache-Control \"max-age=315360000, public, must-revalidate, proxy-revalidate\""))
+			Expect(result).ToNot(ContainSubstring(`Header set Content-Encoding`))
 		})
 
 		it("generates an httpd.conf and disables HTTPS redirection", func() {
@@ -84,6 +101,7 @@ func testPhpAppConfig(t *testing.T, when spec.G, it spec.S) {
 				WebDirectory:         "htdocs",
 				FpmSocket:            "127.0.0.1:9000",
 				DisableHTTPSRedirect: true,
+				Proxy:                defaultProxy,
 			}
 
 			err := ProcessTemplateToFile(HttpdConfTemplate, filepath.Join(f.Home, "httpd.conf"), cfg)
@@ -97,9 +115,12 @@ func testPhpAppConfig(t *testing.T, when spec.G, it spec.S) {
 
 		it("generates an nginx.conf from the template", func() {
 			cfg := NginxConfig{
-				AppRoot:      "/app",
-				WebDirectory: "public",
-				FpmSocket:    "/tmp/php-fpm.socket",
+				AppRoot:         "/app",
+				WebDirectory:    "public",
+				FpmSocket:       "/tmp/php-fpm.socket",
+				Proxy:           defaultProxy,
+				AccessLogFormat: AccessLogExtended,
+				StaticAssets:    StaticAssets{CacheTTL: DefaultCacheTTL, GzipTypes: DefaultGzipTypes},
 			}
 
 			err := ProcessTemplateToFile(NginxConfTemplate, filepath.Join(f.Home, "nginx.conf"), cfg)
@@ -110,11 +131,18 @@ func testPhpAppConfig(t *testing.T, when spec.G, it spec.S) {
 			Expect(result).To(ContainSubstring(`root               /app/public;`))
 			Expect(result).To(ContainSubstring(`server unix:/tmp/php-fpm.socket;`))
 			Expect(result).To(ContainSubstring(`listen       {{env "PORT"}}  default_server;`))
+			Expect(result).To(ContainSubstring(`access_log  /dev/stdout  extended;`))
+			Expect(result).To(ContainSubstring("real_ip_header         X-Forwarded-For;\n        set_real_ip_from       10.0.0.0/8;\n        set_real_ip_from       172.16.0.0/12;\n        set_real_ip_from       192.168.0.0/16;\n"))
+			Expect(result).To(ContainSubstring(`map $http_x_forwarded_proto $proxy_https {`))
+			Expect(result).ToNot(ContainSubstring(`$forwarded_proto`))
 			Expect(result).To(ContainSubstring(`map $http_x_forwarded_proto $redirect_to_https {`))
 			Expect(result).To(ContainSubstring(`if ($redirect_to_https = "yes") {`))
 			Expect(result).To(ContainSubstring(`return 301 https://$http_host$request_uri;`))
 			Expect(string(result)).To(ContainSubstring(`include /app/.nginx.conf.d/*-server.conf`))
 			Expect(string(result)).To(ContainSubstring(`include /app/.nginx.conf.d/*-http.conf`))
+			Expect(result).To(ContainSubstring("location ~* \\.(?:css|gif|ico|jpeg|jpg|js|png)$ {\n            expires         max;"))
+			Expect(result).To(ContainSubstring(`gzip_types         text/plain text/xml text/css text/javascript application/javascript application/json`))
+			Expect(result).ToNot(ContainSubstring(`gzip_static`))
 		})
 
 		it("generates an nginx.conf and disables HTTPS redirection", func() {
@@ -123,6 +151,7 @@ func testPhpAppConfig(t *testing.T, when spec.G, it spec.S) {
 				WebDirectory:         "public",
 				FpmSocket:            "/tmp/php-fpm.socket",
 				DisableHTTPSRedirect: true,
+				Proxy:                defaultProxy,
 			}
 
 			err := ProcessTemplateToFile(NginxConfTemplate, filepath.Join(f.Home, "nginx.conf"), cfg)
@@ -135,6 +164,505 @@ func testPhpAppConfig(t *testing.T, when spec.G, it spec.S) {
 			Expect(result).ToNot(ContainSubstring(`return 301 https://$http_host$request_uri;`))
 		})
 
+		when("custom proxy settings are given", func() {
+			var proxy Proxy
+
+			it.Before(func() {
+				proxy = Proxy{
+					TrustedRanges:  []string{"203.0.113.0/24", "198.51.100.7"},
+					ClientIPHeader: "CF-Connecting-IP",
+					ProtoHeader:    "X-Scheme",
+					Forwarded:      true,
+				}
+			})
+
+			it("generates an httpd.conf trusting the given proxies and headers", func() {
+				cfg := HttpdConfig{
+					AppRoot:      "/app",
+					WebDirectory: "htdocs",
+					FpmSocket:    "127.0.0.1:9000",
+					Proxy:        proxy,
+				}
+
+				err := ProcessTemplateToFile(HttpdConfTemplate, filepath.Join(f.Home, "httpd.conf"), cfg)
+				Expect(err).ToNot(HaveOccurred())
+
+				result, err := ioutil.ReadFile(filepath.Join(f.Home, "httpd.conf"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(ContainSubstring(`RemoteIpHeader CF-Connecting-IP`))
+				Expect(result).To(ContainSubstring("RemoteIpInternalProxy 203.0.113.0/24 198.51.100.7\n"))
+				Expect(result).To(ContainSubstring("SetEnvIf X-Scheme https HTTPS=on\nSetEnvIf Forwarded proto=\"?https HTTPS=on\n"))
+				Expect(result).To(ContainSubstring(`RewriteCond %{HTTP:X-Scheme}%{HTTP:Forwarded} !=""`))
+				Expect(result).To(ContainSubstring("RewriteCond %{HTTP:X-Scheme} !https [NC]\nRewriteCond %{HTTP:Forwarded} !proto=\"?https [NC]\nRewriteRule"))
+			})
+
+			it("generates an nginx.conf trusting the given proxies and headers", func() {
+				cfg := NginxConfig{
+					AppRoot:      "/app",
+					WebDirectory: "public",
+					FpmSocket:    "/tmp/php-fpm.socket",
+					Proxy:        proxy,
+				}
+
+				err := ProcessTemplateToFile(NginxConfTemplate, filepath.Join(f.Home, "nginx.conf"), cfg)
+				Expect(err).ToNot(HaveOccurred())
+
+				result, err := ioutil.ReadFile(filepath.Join(f.Home, "nginx.conf"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(ContainSubstring("real_ip_header         CF-Connecting-IP;\n        set_real_ip_from       203.0.113.0/24;\n        set_real_ip_from       198.51.100.7;\n"))
+				Expect(result).To(ContainSubstring(`map $http_forwarded $forwarded_proto {`))
+				Expect(result).To(ContainSubstring(`default                  $http_x_scheme;`))
+				Expect(result).To(ContainSubstring(`map $forwarded_proto $proxy_https {`))
+				Expect(result).To(ContainSubstring(`map $forwarded_proto $redirect_to_https {`))
+			})
+		})
+
+		when("no proxies are trusted", func() {
+			var proxy Proxy
+
+			it.Before(func() {
+				proxy = Proxy{
+					TrustedRanges:  []string{},
+					ClientIPHeader: "X-Forwarded-For",
+					ProtoHeader:    "X-Forwarded-Proto",
+				}
+			})
+
+			it("generates an httpd.conf which does not read the client IP header", func() {
+				cfg := HttpdConfig{
+					AppRoot:      "/app",
+					WebDirectory: "htdocs",
+					FpmSocket:    "127.0.0.1:9000",
+					Proxy:        proxy,
+				}
+
+				err := ProcessTemplateToFile(HttpdConfTemplate, filepath.Join(f.Home, "httpd.conf"), cfg)
+				Expect(err).ToNot(HaveOccurred())
+
+				result, err := ioutil.ReadFile(filepath.Join(f.Home, "httpd.conf"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).ToNot(ContainSubstring("RemoteIpHeader"))
+				Expect(result).ToNot(ContainSubstring("RemoteIpInternalProxy"))
+			})
+
+			it("generates an nginx.conf which does not read the client IP header", func() {
+				cfg := NginxConfig{
+					AppRoot:      "/app",
+					WebDirectory: "public",
+					FpmSocket:    "/tmp/php-fpm.socket",
+					Proxy:        proxy,
+				}
+
+				err := ProcessTemplateToFile(NginxConfTemplate, filepath.Join(f.Home, "nginx.conf"), cfg)
+				Expect(err).ToNot(HaveOccurred())
+
+				result, err := ioutil.ReadFile(filepath.Join(f.Home, "nginx.conf"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).ToNot(ContainSubstring("real_ip_header"))
+				Expect(result).ToNot(ContainSubstring("set_real_ip_from"))
+			})
+		})
+
+		when("security headers are set", func() {
+			var headers []Header
+
+			it.Before(func() {
+				headers = SecurityHeaders{
+					Profile: SecurityHeadersRecommended,
+					Headers: map[string]string{"content-security-policy": `default-src 'self'; img-src "data:"`},
+				}.Resolve()
+			})
+
+			it("generates an httpd.conf which sets the headers", func() {
+				cfg := HttpdConfig{
+					AppRoot:         "/app",
+					WebDirectory:    "htdocs",
+					FpmSocket:       "127.0.0.1:9000",
+					Proxy:           defaultProxy,
+					SecurityHeaders: headers,
+				}
+
+				err := ProcessTemplateToFile(HttpdConfTemplate, filepath.Join(f.Home, "httpd.conf"), cfg)
+				Expect(err).ToNot(HaveOccurred())
+
+				result, err := ioutil.ReadFile(filepath.Join(f.Home, "httpd.conf"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(ContainSubstring(`Header always set Content-Security-Policy "default-src 'self'; img-src \"data:\""`))
+				Expect(result).To(ContainSubstring(`Header always set Strict-Transport-Security "max-age=31536000"`))
+				Expect(result).To(ContainSubstring(`Header always set X-Frame-Options "SAMEORIGIN"`))
+			})
+
+			it("generates an nginx.conf which sets the headers in every location adding headers", func() {
+				cfg := NginxConfig{
+					AppRoot:         "/app",
+					WebDirectory:    "public",
+					FpmSocket:       "/tmp/php-fpm.socket",
+					Proxy:           defaultProxy,
+					SecurityHeaders: headers,
+					StaticAssets:    StaticAssets{CacheTTL: DefaultCacheTTL},
+				}
+
+				err := ProcessTemplateToFile(NginxConfTemplate, filepath.Join(f.Home, "nginx.conf"), cfg)
+				Expect(err).ToNot(HaveOccurred())
+
+				result, err := ioutil.ReadFile(filepath.Join(f.Home, "nginx.conf"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(strings.Count(string(result), `add_header      Content-Security-Policy "default-src 'self'; img-src \"data:\"" always;`)).To(Equal(2))
+				Expect(strings.Count(string(result), `add_header      X-Content-Type-Options "nosniff" always;`)).To(Equal(2))
+			})
+
+			it("generates a router script which sets the headers", func() {
+				err := ProcessTemplateToFile(PhpRouterTemplate, filepath.Join(f.Home, "router.php"), PhpRouterConfig{SecurityHeaders: headers})
+				Expect(err).ToNot(HaveOccurred())
+
+				result, err := ioutil.ReadFile(filepath.Join(f.Home, "router.php"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(ContainSubstring(`header('Content-Security-Policy: default-src \'self\'; img-src "data:"');`))
+				Expect(result).To(ContainSubstring(`header('Referrer-Policy: strict-origin-when-cross-origin');`))
+			})
+
+			it("generates a router script which serves files itself, as the built-in server drops the headers otherwise", func() {
+				err := ProcessTemplateToFile(PhpRouterTemplate, filepath.Join(f.Home, "router.php"), PhpRouterConfig{SecurityHeaders: headers})
+				Expect(err).ToNot(HaveOccurred())
+
+				result, err := ioutil.ReadFile(filepath.Join(f.Home, "router.php"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(ContainSubstring("        'css' => 'text/css',\n"))
+				Expect(result).To(ContainSubstring("readfile($file);\n    return true;"))
+				Expect(result).To(ContainSubstring("if (strpos($file, $documentRoot . '/') !== 0) {\n        http_response_code(404);"))
+				Expect(result).ToNot(ContainSubstring("is_file($file . '/index.html')) {\n    return false;"))
+			})
+		})
+
+		it("generates a router script which routes through the front controller", func() {
+			err := ProcessTemplateToFile(PhpRouterTemplate, filepath.Join(f.Home, "router.php"), PhpRouterConfig{FrontController: "public/it's.php"})
+			Expect(err).ToNot(HaveOccurred())
+
+			result, err := ioutil.ReadFile(filepath.Join(f.Home, "router.php"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(ContainSubstring(`$frontController = '/public/it\'s.php';`))
+			Expect(result).To(ContainSubstring(`return require $_SERVER['SCRIPT_FILENAME'];`))
+			Expect(result).To(ContainSubstring("is_file($file . '/index.html')) {\n    return false;"))
+			Expect(result).ToNot(ContainSubstring("readfile"))
+		})
+
+		when("the json access log format is selected", func() {
+			it("generates an httpd.conf which logs json", func() {
+				cfg := HttpdConfig{
+					AppRoot:         "/app",
+					WebDirectory:    "htdocs",
+					FpmSocket:       "127.0.0.1:9000",
+					Proxy:           Proxy{ClientIPHeader: "CF-Connecting-IP"},
+					AccessLogFormat: AccessLogJSON,
+					RequestID:       RequestID{Header: "X-Request-Id"},
+				}
+
+				err := ProcessTemplateToFile(HttpdConfTemplate, filepath.Join(f.Home, "httpd.conf"), cfg)
+				Expect(err).ToNot(HaveOccurred())
+
+				result, err := ioutil.ReadFile(filepath.Join(f.Home, "httpd.conf"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(ContainSubstring(`\"forwarded_for\":\"%{CF-Connecting-IP}i\"`))
+				Expect(result).To(ContainSubstring(`\"request_time_ms\":%{ms}T`))
+				Expect(result).To(ContainSubstring(`CustomLog "/proc/self/fd/1" json`))
+			})
+
+			it("generates an nginx.conf which logs json", func() {
+				cfg := NginxConfig{
+					AppRoot:         "/app",
+					WebDirectory:    "public",
+					FpmSocket:       "/tmp/php-fpm.socket",
+					Proxy:           Proxy{ClientIPHeader: "CF-Connecting-IP"},
+					AccessLogFormat: AccessLogJSON,
+					RequestID:       RequestID{Header: "X-Request-Id"},
+				}
+
+				err := ProcessTemplateToFile(NginxConfTemplate, filepath.Join(f.Home, "nginx.conf"), cfg)
+				Expect(err).ToNot(HaveOccurred())
+
+				result, err := ioutil.ReadFile(filepath.Join(f.Home, "nginx.conf"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(ContainSubstring(`log_format json escape=json '{"time":"$time_iso8601"`))
+				Expect(result).To(ContainSubstring(`"forwarded_for":"$http_cf_connecting_ip"`))
+				Expect(result).To(ContainSubstring(`"upstream_response_time":"$upstream_response_time"`))
+				Expect(result).To(ContainSubstring(`access_log  /dev/stdout  json;`))
+			})
+		})
+
+		when("request IDs are enabled", func() {
+			var requestID RequestID
+
+			it.Before(func() {
+				requestID = RequestID{Enabled: true, Header: "X-Correlation-Id"}
+			})
+
+			it("generates an httpd.conf which generates, logs and passes on request IDs", func() {
+				cfg := HttpdConfig{
+					AppRoot:         "/app",
+					WebDirectory:    "htdocs",
+					FpmSocket:       "127.0.0.1:9000",
+					Proxy:           defaultProxy,
+					AccessLogFormat: AccessLogJSON,
+					RequestID:       requestID,
+				}
+
+				err := ProcessTemplateToFile(HttpdConfTemplate, filepath.Join(f.Home, "httpd.conf"), cfg)
+				Expect(err).ToNot(HaveOccurred())
+
+				result, err := ioutil.ReadFile(filepath.Join(f.Home, "httpd.conf"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(ContainSubstring("LoadModule headers_module modules/mod_headers.so\nLoadModule unique_id_module modules/mod_unique_id.so\n"))
+				Expect(result).To(ContainSubstring(`RequestHeader setifempty X-Correlation-Id "%{UNIQUE_ID}e"`))
+				Expect(result).To(ContainSubstring(`\"request_id\":\"%{X-Correlation-Id}i\"`))
+				Expect(result).To(ContainSubstring(`peer_addr=%{c}a request_id=%{X-Correlation-Id}i" extended`))
+			})
+
+			it("generates an nginx.conf which generates, logs and passes on request IDs", func() {
+				cfg := NginxConfig{
+					AppRoot:         "/app",
+					WebDirectory:    "public",
+					FpmSocket:       "/tmp/php-fpm.socket",
+					Proxy:           defaultProxy,
+					AccessLogFormat: AccessLogJSON,
+					RequestID:       requestID,
+				}
+
+				err := ProcessTemplateToFile(NginxConfTemplate, filepath.Join(f.Home, "nginx.conf"), cfg)
+				Expect(err).ToNot(HaveOccurred())
+
+				result, err := ioutil.ReadFile(filepath.Join(f.Home, "nginx.conf"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(ContainSubstring("map $http_x_correlation_id $app_request_id {\n        \"\"          $request_id;\n        default     $http_x_correlation_id;\n    }"))
+				Expect(result).To(ContainSubstring(`"request_id":"$app_request_id"`))
+				Expect(result).To(ContainSubstring(`vcap_request_id=$http_x_vcap_request_id request_id=$app_request_id'`))
+				Expect(result).To(ContainSubstring(`fastcgi_param  HTTP_X_CORRELATION_ID  $app_request_id;`))
+			})
+
+			it("does not generate request IDs unless enabled", func() {
+				cfg := NginxConfig{
+					AppRoot:         "/app",
+					WebDirectory:    "public",
+					FpmSocket:       "/tmp/php-fpm.socket",
+					Proxy:           defaultProxy,
+					AccessLogFormat: AccessLogJSON,
+					RequestID:       RequestID{Header: "X-Request-Id"},
+				}
+
+				err := ProcessTemplateToFile(NginxConfTemplate, filepath.Join(f.Home, "nginx.conf"), cfg)
+				Expect(err).ToNot(HaveOccurred())
+
+				result, err := ioutil.ReadFile(filepath.Join(f.Home, "nginx.conf"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(ContainSubstring(`"request_id":"$http_x_request_id"`))
+				Expect(result).ToNot(ContainSubstring(`$app_request_id`))
+			})
+		})
+
+		when("trace IDs are logged", func() {
+			it("generates an httpd.conf which logs the trace ID of the caller", func() {
+				cfg := HttpdConfig{
+					AppRoot:         "/app",
+					WebDirectory:    "htdocs",
+					FpmSocket:       "127.0.0.1:9000",
+					Proxy:           defaultProxy,
+					AccessLogFormat: AccessLogExtended,
+					RequestID:       RequestID{Header: "X-Request-Id"},
+					LogTraceIDs:     true,
+				}
+
+				err := ProcessTemplateToFile(HttpdConfTemplate, filepath.Join(f.Home, "httpd.conf"), cfg)
+				Expect(err).ToNot(HaveOccurred())
+
+				result, err := ioutil.ReadFile(filepath.Join(f.Home, "httpd.conf"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(ContainSubstring("SetEnvIf x-datadog-trace-id \"^([0-9]+)$\" TRACE_ID=$1\n"))
+				Expect(result).ToNot(ContainSubstring("traceparent"))
+				Expect(result).To(ContainSubstring(`peer_addr=%{c}a trace_id=%{TRACE_ID}e" extended`))
+				Expect(result).To(ContainSubstring(`\"request_id\":\"%{X-Request-Id}i\",\"trace_id\":\"%{TRACE_ID}e\",`))
+			})
+
+			it("generates an nginx.conf which logs the trace ID of the caller", func() {
+				cfg := NginxConfig{
+					AppRoot:         "/app",
+					WebDirectory:    "public",
+					FpmSocket:       "/tmp/php-fpm.socket",
+					Proxy:           defaultProxy,
+					AccessLogFormat: AccessLogExtended,
+					RequestID:       RequestID{Header: "X-Request-Id"},
+					LogTraceIDs:     true,
+				}
+
+				err := ProcessTemplateToFile(NginxConfTemplate, filepath.Join(f.Home, "nginx.conf"), cfg)
+				Expect(err).ToNot(HaveOccurred())
+
+				result, err := ioutil.ReadFile(filepath.Join(f.Home, "nginx.conf"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(ContainSubstring("map $http_x_datadog_trace_id $trace_id {\n        \"~^[0-9]+$\"     $http_x_datadog_trace_id;\n        default         \"\";\n    }"))
+				Expect(result).ToNot(ContainSubstring("traceparent"))
+				Expect(result).To(ContainSubstring(`vcap_request_id=$http_x_vcap_request_id trace_id=$trace_id';`))
+				Expect(result).To(ContainSubstring(`"request_id":"$http_x_request_id","trace_id":"$trace_id",`))
+			})
+
+			it("does not log trace IDs otherwise", func() {
+				cfg := HttpdConfig{
+					AppRoot:         "/app",
+					WebDirectory:    "htdocs",
+					FpmSocket:       "127.0.0.1:9000",
+					Proxy:           defaultProxy,
+					AccessLogFormat: AccessLogExtended,
+				}
+
+				err := ProcessTemplateToFile(HttpdConfTemplate, filepath.Join(f.Home, "httpd.conf"), cfg)
+				Expect(err).ToNot(HaveOccurred())
+
+				result, err := ioutil.ReadFile(filepath.Join(f.Home, "httpd.conf"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).ToNot(ContainSubstring("TRACE_ID"))
+			})
+		})
+
+		when("a static asset policy is set", func() {
+			var staticAssets StaticAssets
+
+			it.Before(func() {
+				staticAssets = StaticAssets{
+					CacheTTL:      map[string]string{"js": "1y", "woff2": "1y", "svg": "30d", "png": "off"},
+					GzipTypes:     []string{"text/html", "application/javascript", "image/svg+xml"},
+					Precompressed: true,
+				}
+			})
+
+			it("generates an httpd.conf with the policy", func() {
+				cfg := HttpdConfig{
+					AppRoot:         "/app",
+					WebDirectory:    "htdocs",
+					FpmSocket:       "127.0.0.1:9000",
+					Proxy:           defaultProxy,
+					AccessLogFormat: AccessLogExtended,
+					StaticAssets:    staticAssets,
+				}
+
+				err := ProcessTemplateToFile(HttpdConfTemplate, filepath.Join(f.Home, "httpd.conf"), cfg)
+				Expect(err).ToNot(HaveOccurred())
+
+				result, err := ioutil.ReadFile(filepath.Join(f.Home, "httpd.conf"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(ContainSubstring(`AddOutputFilterByType DEFLATE text/html application/javascript image/svg+xml`))
+				Expect(result).To(ContainSubstring(`<FilesMatch "(?i)\.(?:js|woff2)(?:\.br|\.gz)?$">`))
+				Expect(result).To(ContainSubstring(`Header set Cache-Control "max-age=31536000, public, must-revalidate, proxy-revalidate"`))
+				Expect(result).To(ContainSubstring(`Header set Cache-Control "max-age=2592000, public, must-revalidate, proxy-revalidate"`))
+				Expect(result).ToNot(ContainSubstring(`png`))
+				Expect(result).To(ContainSubstring(`RewriteRule "^(.+\.(?:htm|html|js|mjs|svg))$" "$1.br" [QSA,L]`))
+				Expect(result).To(ContainSubstring(`RewriteRule "\.svg\.(?:br|gz)$" "-" [T=image/svg+xml,E=no-gzip:1]`))
+				Expect(result).To(ContainSubstring(`Header set Content-Encoding gzip`))
+			})
+
+			it("generates an nginx.conf with the policy", func() {
+				cfg := NginxConfig{
+					AppRoot:         "/app",
+					WebDirectory:    "public",
+					FpmSocket:       "/tmp/php-fpm.socket",
+					Proxy:           defaultProxy,
+					AccessLogFormat: AccessLogExtended,
+					StaticAssets:    staticAssets,
+				}
+
+				err := ProcessTemplateToFile(NginxConfTemplate, filepath.Join(f.Home, "nginx.conf"), cfg)
+				Expect(err).ToNot(HaveOccurred())
+
+				result, err := ioutil.ReadFile(filepath.Join(f.Home, "nginx.conf"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(ContainSubstring(`gzip_types         application/javascript image/svg+xml;`))
+				Expect(result).To(ContainSubstring(`gzip_static        on;`))
+				Expect(result).To(ContainSubstring("location ~* \\.(?:js|woff2)$ {\n            expires         1y;"))
+				Expect(result).To(ContainSubstring("location ~* \\.(?:svg)$ {\n            expires         30d;"))
+				Expect(result).ToNot(ContainSubstring(`png)$`))
+			})
+		})
+
+		render := func(template, name string, cfg interface{}) string {
+			Expect(ProcessTemplateToFile(template, filepath.Join(f.Home, name), cfg)).To(Succeed())
+
+			result, err := ioutil.ReadFile(filepath.Join(f.Home, name))
+			Expect(err).ToNot(HaveOccurred())
+			return string(result)
+		}
+
+		baseHttpdConfig := func() HttpdConfig {
+			return HttpdConfig{
+				AppRoot:         "/app",
+				WebDirectory:    "htdocs",
+				FpmSocket:       "127.0.0.1:9000",
+				Proxy:           defaultProxy,
+				AccessLogFormat: AccessLogExtended,
+			}
+		}
+
+		baseNginxConfig := func() NginxConfig {
+			return NginxConfig{
+				AppRoot:         "/app",
+				WebDirectory:    "public",
+				FpmSocket:       "/tmp/php-fpm.socket",
+				Proxy:           defaultProxy,
+				AccessLogFormat: AccessLogExtended,
+			}
+		}
+
+		when("a max request body is set", func() {
+			it("generates an httpd.conf which limits the request body in bytes", func() {
+				cfg := baseHttpdConfig()
+				cfg.MaxRequestBody = "100M"
+
+				Expect(render(HttpdConfTemplate, "httpd.conf", cfg)).To(ContainSubstring("\nLimitRequestBody 104857600\n"))
+			})
+
+			it("caps the httpd.conf limit at 2GB", func() {
+				Expect(HttpdConfig{MaxRequestBody: "4g"}.LimitRequestBody()).To(Equal(int64(2147483647)))
+				Expect(HttpdConfig{}.LimitRequestBody()).To(Equal(int64(8388608)))
+			})
+
+			it("generates an nginx.conf which limits the request body", func() {
+				cfg := baseNginxConfig()
+				cfg.MaxRequestBody = "100M"
+
+				Expect(render(NginxConfTemplate, "nginx.conf", cfg)).To(ContainSubstring("client_max_body_size   100M;"))
+			})
+
+			it("generates a php.ini which accepts posts & uploads of the same size", func() {
+				result := render(PhpIniTemplate, "php.ini", PhpIniConfig{AppRoot: "/app", LibDirectory: "lib", MaxRequestBody: "100M"})
+				Expect(result).To(ContainSubstring("\npost_max_size = 100M\n"))
+				Expect(result).To(ContainSubstring("\nupload_max_filesize = 100M\n"))
+			})
+		})
+
+		when("a request timeout is set", func() {
+			it("generates an httpd.conf which waits on php-fpm past the timeout", func() {
+				cfg := baseHttpdConfig()
+				cfg.RequestTimeout = 120
+
+				result := render(HttpdConfTemplate, "httpd.conf", cfg)
+				Expect(result).To(ContainSubstring("\nTimeout 130\n"))
+				Expect(result).To(ContainSubstring("ProxySet disablereuse=On retry=0 timeout=130\n"))
+			})
+
+			it("generates an nginx.conf which waits on php-fpm past the timeout", func() {
+				cfg := baseNginxConfig()
+				cfg.RequestTimeout = 120
+
+				Expect(render(NginxConfTemplate, "nginx.conf", cfg)).To(ContainSubstring("fastcgi_read_timeout  130s;"))
+			})
+
+			it("generates a php-fpm.conf which kills workers shortly after the timeout", func() {
+				result := render(PhpFpmConfTemplate, "php-fpm.conf", PhpFpmConfig{Listen: "127.0.0.1:9000", RequestTimeout: 120})
+				Expect(result).To(ContainSubstring("\nrequest_terminate_timeout = 125\n"))
+			})
+
+			it("generates a php.ini which limits the execution time", func() {
+				result := render(PhpIniTemplate, "php.ini", PhpIniConfig{AppRoot: "/app", LibDirectory: "lib", RequestTimeout: 120})
+				Expect(result).To(ContainSubstring("\nmax_execution_time = 120\n"))
+			})
+		})
+
 		it("generates a php.ini from the template", func() {
 			cfg := PhpIniConfig{
 				AppRoot:      "/app",
@@ -163,6 +691,74 @@ func testPhpAppConfig(t *testing.T, when spec.G, it spec.S) {
 			Expect(result).To(ContainSubstring(`zend_extension = xdebug.so`))
 		})
 
+		it("generates a php.ini for the development profile", func() {
+			cfg := PhpIniConfig{AppRoot: "/app", LibDirectory: "lib", Profile: IniProfileDevelopment}
+
+			Expect(ProcessTemplateToFile(PhpIniTemplate, filepath.Join(f.Home, "php.ini"), cfg)).To(Succeed())
+
+			result, err := ioutil.ReadFile(filepath.Join(f.Home, "php.ini"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(ContainSubstring("\nerror_reporting = E_ALL\n"))
+			Expect(result).To(ContainSubstring("\ndisplay_errors = On\n"))
+			Expect(result).To(ContainSubstring("\nzend.assertions = 1\n"))
+			Expect(result).To(ContainSubstring("\nopcache.revalidate_freq=0\n"))
+			Expect(result).To(ContainSubstring("\n;open_basedir =\n"))
+		})
+
+		it("generates a php.ini which revalidates scripts on every request, with live reload", func() {
+			cfg := PhpIniConfig{AppRoot: "/app", LibDirectory: "lib", LiveReload: true}
+
+			Expect(ProcessTemplateToFile(PhpIniTemplate, filepath.Join(f.Home, "php.ini"), cfg)).To(Succeed())
+
+			result, err := ioutil.ReadFile(filepath.Join(f.Home, "php.ini"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(ContainSubstring("\nopcache.validate_timestamps=1\n"))
+			Expect(result).To(ContainSubstring("\nopcache.revalidate_freq=0\n"))
+			Expect(result).To(ContainSubstring("\ndisplay_errors = Off\n"))
+		})
+
+		it("generates a php.ini for the hardened profile", func() {
+			cfg := PhpIniConfig{
+				AppRoot:         "/app",
+				LibDirectory:    "lib",
+				PhpHome:         "/php/home",
+				Profile:         IniProfileHardened,
+				ConfigDirectory: "/layers/php-web/etc",
+			}
+
+			Expect(ProcessTemplateToFile(PhpIniTemplate, filepath.Join(f.Home, "php.ini"), cfg)).To(Succeed())
+
+			result, err := ioutil.ReadFile(filepath.Join(f.Home, "php.ini"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(ContainSubstring(`open_basedir = "/app:/app/lib:/php/home/lib/php:/layers/php-web/etc:/tmp"`))
+			Expect(result).To(ContainSubstring("\nsys_temp_dir = \"/tmp\"\n"))
+			Expect(result).To(ContainSubstring("\nupload_tmp_dir = \"/tmp\"\n"))
+			Expect(result).To(ContainSubstring("\ndisable_functions = exec,passthru,shell_exec,system,proc_open,popen,pcntl_exec\n"))
+			Expect(result).To(ContainSubstring("\nallow_url_fopen = Off\n"))
+			Expect(result).To(ContainSubstring("\nsession.cookie_secure = On\n"))
+			Expect(result).To(ContainSubstring("\nsession.cookie_samesite = Strict\n"))
+			Expect(result).To(ContainSubstring("\ndisplay_errors = Off\n"))
+		})
+
+		it("limits the hardened profile to the build's temporary directory", func() {
+			cfg := PhpIniConfig{
+				AppRoot:         "/app",
+				LibDirectory:    "lib",
+				PhpHome:         "/php/home",
+				Profile:         IniProfileHardened,
+				ConfigDirectory: "/layers/php-web/etc",
+				TempDirectory:   "/home/cnb/tmp/",
+			}
+
+			Expect(ProcessTemplateToFile(PhpIniTemplate, filepath.Join(f.Home, "php.ini"), cfg)).To(Succeed())
+
+			result, err := ioutil.ReadFile(filepath.Join(f.Home, "php.ini"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(ContainSubstring(`open_basedir = "/app:/app/lib:/php/home/lib/php:/layers/php-web/etc:/home/cnb/tmp"`))
+			Expect(result).To(ContainSubstring("\nsys_temp_dir = \"/home/cnb/tmp\"\n"))
+			Expect(result).To(ContainSubstring("\nupload_tmp_dir = \"/home/cnb/tmp\"\n"))
+		})
+
 		it("generates a php-fpm.conf from the template", func() {
 			cfg := PhpFpmConfig{
 				Include: "/php/home/.php-fpm.d/*.conf",
@@ -193,22 +789,39 @@ func testPhpAppConfig(t *testing.T, when spec.G, it spec.S) {
 			loaded, err := LoadBuildpackYAML(f.Detect.Application.Root)
 
 			Expect(err).To(Succeed())
-			Expect(loaded).To(Equal(BuildpackYAML{
-				Config{
-					Version:             "",
-					WebServer:           "php-server",
-					WebDirectory:        "htdocs",
-					LibDirectory:        "lib",
-					Script:              "",
-					ServerAdmin:         "admin@localhost",
-					EnableHTTPSRedirect: true,
-					Redis: Redis{
-						SessionStoreServiceName: "redis-sessions",
-					},
-					Memcached: Memcached{
-						SessionStoreServiceName: "memcached-sessions",
-					},
+			Expect(loaded.Config).To(Equal(Config{
+				Version:             "",
+				WebServer:           "php-server",
+				WebDirectory:        "htdocs",
+				LibDirectory:        "lib",
+				Script:              "",
+				ServerAdmin:         "admin@localhost",
+				EnableHTTPSRedirect: true,
+				Redis: Redis{
+					SessionStoreServiceName: "redis-sessions",
 				},
+				Memcached: Memcached{
+					SessionStoreServiceName: "memcached-sessions",
+				},
+				Proxy: defaultProxy,
+				SecurityHeaders: SecurityHeaders{
+					Profile: SecurityHeadersNone,
+				},
+				AccessLogFormat: AccessLogExtended,
+				RequestID: RequestID{
+					Header: "X-Request-Id",
+				},
+				StaticAssets: StaticAssets{
+					CacheTTL:           DefaultCacheTTL,
+					GzipTypes:          DefaultGzipTypes,
+					PrecompressMinSize: 1024,
+				},
+				PhpServer: PhpServer{
+					FrontController: "index.php",
+				},
+				IniProfile:     IniProfileProduction,
+				MaxRequestBody: DefaultMaxRequestBody,
+				RequestTimeout: DefaultRequestTimeout,
 			}))
 		})
 
@@ -232,11 +845,223 @@ func testPhpAppConfig(t *testing.T, when spec.G, it spec.S) {
 					Memcached: Memcached{
 						SessionStoreServiceName: "memcached-sessions",
 					},
+					Proxy: defaultProxy,
+					SecurityHeaders: SecurityHeaders{
+						Profile: SecurityHeadersNone,
+					},
+					AccessLogFormat: AccessLogExtended,
+					RequestID: RequestID{
+						Header: "X-Request-Id",
+					},
+					StaticAssets: StaticAssets{
+						CacheTTL:           DefaultCacheTTL,
+						GzipTypes:          DefaultGzipTypes,
+						PrecompressMinSize: 1024,
+					},
+					PhpServer: PhpServer{
+						FrontController: "index.php",
+					},
+					IniProfile:     IniProfileProduction,
+					MaxRequestBody: DefaultMaxRequestBody,
+					RequestTimeout: DefaultRequestTimeout,
 				},
 			}
 
 			Expect(err).To(Succeed())
-			Expect(loaded).To(Equal(actual))
+			Expect(loaded.Config).To(Equal(actual.Config))
+			Expect(loaded.IsSet("php.webserver")).To(BeTrue())
+			Expect(loaded.IsSet("php.enable_https_redirect")).To(BeTrue())
+			Expect(loaded.IsSet("php.webdirectory")).To(BeFalse())
+		})
+
+		it("can load proxy settings", func() {
+			yaml := "{'php': {'proxy': {'trusted_ranges': ['203.0.113.0/24'], 'forwarded': true}}}"
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), yaml)
+
+			loaded, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).To(Succeed())
+			Expect(loaded.Config.Proxy).To(Equal(Proxy{
+				TrustedRanges:  []string{"203.0.113.0/24"},
+				ClientIPHeader: "X-Forwarded-For",
+				ProtoHeader:    "X-Forwarded-Proto",
+				Forwarded:      true,
+			}))
+		})
+
+		it("rejects an unknown security headers profile", func() {
+			yaml := "{'php': {'security_headers': {'profile': 'paranoid'}}}"
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), yaml)
+
+			_, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).To(MatchError(ContainSubstring(`invalid php.security_headers.profile "paranoid", must be one of: none, recommended`)))
+		})
+
+		it("rejects an unknown access log format", func() {
+			yaml := "{'php': {'access_log_format': 'xml'}}"
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), yaml)
+
+			_, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).To(MatchError(ContainSubstring(`invalid php.access_log_format "xml", must be one of: common, combined, extended, json`)))
+		})
+
+		it("merges the static asset cache policy with the defaults", func() {
+			yaml := "{'php': {'static_assets': {'cache_ttl': {'js': '1y', 'wasm': '30d'}}}}"
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), yaml)
+
+			loaded, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).To(Succeed())
+			Expect(loaded.Config.StaticAssets.CacheTTL).To(HaveKeyWithValue("js", "1y"))
+			Expect(loaded.Config.StaticAssets.CacheTTL).To(HaveKeyWithValue("wasm", "30d"))
+			Expect(loaded.Config.StaticAssets.CacheTTL).To(HaveKeyWithValue("css", "max"))
+			Expect(DefaultCacheTTL).To(HaveKeyWithValue("js", "max"))
+		})
+
+		it("rejects an invalid cache TTL", func() {
+			yaml := "{'php': {'static_assets': {'cache_ttl': {'js': 'forever'}}}}"
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), yaml)
+
+			_, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).To(MatchError(ContainSubstring(`invalid php.static_assets.cache_ttl value "forever" for "js"`)))
+		})
+
+		it("rejects invalid proxy settings", func() {
+			yaml := "{'php': {'proxy': {'trusted_ranges': ['not-a-cidr'], 'proto_header': 'X-Proto; evil'}}}"
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), yaml)
+
+			_, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).To(MatchError(ContainSubstring(`invalid php.proxy.trusted_ranges entry "not-a-cidr"`)))
+		})
+
+		it("rejects a built-in web server router outside of the application", func() {
+			yaml := "{'php': {'php_server': {'router': '../router.php'}}}"
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), yaml)
+
+			_, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).To(MatchError(ContainSubstring(`invalid php.php_server.router "../router.php"`)))
+		})
+
+		it("rejects a declared web process", func() {
+			yaml := "{'php': {'processes': {'web': 'php -S 0.0.0.0:8080'}}}"
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), yaml)
+
+			_, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).To(MatchError(ContainSubstring(`invalid php.processes type "web"`)))
+		})
+
+		it("rejects a worker named after a process run by procmgr", func() {
+			yaml := "{'php': {'workers': {'php-fpm': {'command': 'php worker.php'}}}}"
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), yaml)
+
+			_, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).To(MatchError(ContainSubstring(`invalid php.workers name "php-fpm"`)))
+		})
+
+		it("rejects an invalid schedule", func() {
+			yaml := "{'php': {'schedule': ['* * 32 * * php artisan schedule:run']}}"
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), yaml)
+
+			_, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).To(MatchError(ContainSubstring(`invalid php.schedule entry: invalid day of month`)))
+		})
+
+		it("loads ini directives as written", func() {
+			yaml := "{'php': {'ini': {'memory_limit': '512M', 'max_input_vars': 5000, 'display_errors': Off, 'date.timezone': 'UTC'}}}"
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), yaml)
+
+			loaded, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loaded.Config.Ini).To(Equal(IniDirectives{
+				"memory_limit":   "512M",
+				"max_input_vars": "5000",
+				"display_errors": "Off",
+				"date.timezone":  "UTC",
+			}))
+			Expect(loaded.UnknownKeys()).To(BeEmpty())
+		})
+
+		it("rejects an invalid ini directive", func() {
+			yaml := "{'php': {'ini': {'memory limit': '1G'}}}"
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), yaml)
+
+			_, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).To(MatchError(ContainSubstring(`invalid php.ini directive "memory limit"`)))
+		})
+
+		it("rejects max_execution_time in favour of the request timeout", func() {
+			yaml := "{'php': {'ini': {'max_execution_time': 60}}}"
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), yaml)
+
+			_, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).To(MatchError(`invalid php.ini directive "max_execution_time", set php.request_timeout instead`))
+		})
+
+		it("rejects post_max_size & upload_max_filesize in favour of the request body limit", func() {
+			for _, name := range []string{"post_max_size", "upload_max_filesize"} {
+				yaml := fmt.Sprintf("{'php': {'ini': {'%s': '64M'}}}", name)
+				test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), yaml)
+
+				_, err := LoadBuildpackYAML(f.Detect.Application.Root)
+				Expect(err).To(MatchError(fmt.Sprintf(`invalid php.ini directive %q, set php.max_request_body instead`, name)))
+			}
+		})
+
+		it("quotes ini values, unless they are expressions", func() {
+			Expect(QuoteIniValue("Europe/Berlin")).To(Equal(`"Europe/Berlin"`))
+			Expect(QuoteIniValue(`say "hi" to ${USER} \o/; bye`)).To(Equal(`"say \"hi\" to \${USER} \\o/; bye"`))
+			Expect(QuoteIniValue("")).To(Equal(`""`))
+			Expect(QuoteIniValue("512M")).To(Equal("512M"))
+			Expect(QuoteIniValue("E_ALL & ~E_DEPRECATED")).To(Equal("E_ALL & ~E_DEPRECATED"))
+		})
+
+		it("rejects an invalid max request body", func() {
+			yaml := "{'php': {'max_request_body': '10MB'}}"
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), yaml)
+
+			_, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).To(MatchError(`invalid php.max_request_body "10MB", must be a number of bytes with an optional K, M or G suffix`))
+		})
+
+		it("rejects a max request body which would overflow", func() {
+			yaml := "{'php': {'max_request_body': '18014398509481984G'}}"
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), yaml)
+
+			_, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).To(MatchError(ContainSubstring(`invalid php.max_request_body "18014398509481984G"`)))
+		})
+
+		it("rejects a request timeout which isn't positive", func() {
+			yaml := "{'php': {'request_timeout': -1}}"
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), yaml)
+
+			_, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).To(MatchError(`invalid php.request_timeout -1, must be a positive number of seconds`))
+		})
+
+		it("rejects an unknown php.ini profile", func() {
+			yaml := "{'php': {'ini_profile': 'staging'}}"
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), yaml)
+
+			_, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).To(MatchError(`invalid php.ini_profile "staging", must be one of: production, development, hardened`))
+		})
+
+		it("normalizes the web server and defaults empty values", func() {
+			yaml := "{'php': {'webserver': 'NGINX', 'webdirectory': '', 'libdirectory': ''}}"
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), yaml)
+
+			loaded, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loaded.Config.WebServer).To(Equal(Nginx))
+			Expect(loaded.Config.WebDirectory).To(Equal("htdocs"))
+			Expect(loaded.Config.LibDirectory).To(Equal("lib"))
+		})
+
+		it("rejects an absolute library directory", func() {
+			yaml := "{'php': {'libdirectory': '/usr/lib'}}"
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), yaml)
+
+			_, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).To(MatchError(`invalid php.libdirectory "/usr/lib", must be a relative path within the application`))
 		})
 
 		it("logs a warning against user-set buildpack.yml config", func() {
@@ -254,10 +1079,13 @@ func testPhpAppConfig(t *testing.T, when spec.G, it spec.S) {
 		}}`
 			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), yaml)
 
+			loaded, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).ToNot(HaveOccurred())
+
 			buf := bytes.NewBuffer(nil)
 
 			logger := logger.Logger{Logger: bp.NewLogger(buf, buf)}
-			Expect(WarnBuildpackYAML(logger, "1.2.3", f.Detect.Application.Root)).To(Succeed())
+			WarnBuildpackYAML(logger, "1.2.3", loaded)
 			Expect(buf.String()).To(ContainSubstring(`WARNING: Setting PHP configurations through buildpack.yml will be deprecated soon in buildpack v2.0.0.`))
 			Expect(buf.String()).To(ContainSubstring("Buildpack.yml values will be replaced by environment variables in the next major version:"))
 			Expect(buf.String()).To(ContainSubstring("php.version -> BP_PHP_VERSION"))
@@ -271,16 +1099,277 @@ func testPhpAppConfig(t *testing.T, when spec.G, it spec.S) {
 			Expect(buf.String()).To(ContainSubstring("php.memcached.session_store_service_name -> use a service binding"))
 		})
 
+		it("logs a warning against config explicitly set to its default", func() {
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), "{'php': {'webserver': 'php-server', 'webdirectory': 'htdocs'}}")
+
+			loaded, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).ToNot(HaveOccurred())
+
+			buf := bytes.NewBuffer(nil)
+			logger := logger.Logger{Logger: bp.NewLogger(buf, buf)}
+			WarnBuildpackYAML(logger, "1.2.3", loaded)
+			Expect(buf.String()).To(ContainSubstring("php.webserver -> BP_PHP_SERVER"))
+			Expect(buf.String()).To(ContainSubstring("php.webdirectory -> BP_PHP_WEB_DIR"))
+			Expect(buf.String()).NotTo(ContainSubstring("php.serveradmin"))
+		})
+
+		it("does not log a deprecation warning when only options without a replacement are set", func() {
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), "{'php': {'ini': {'memory_limit': '256M'}, 'access_log_format': 'json'}}")
+
+			loaded, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).ToNot(HaveOccurred())
+
+			buf := bytes.NewBuffer(nil)
+			logger := logger.Logger{Logger: bp.NewLogger(buf, buf)}
+			WarnBuildpackYAML(logger, "1.2.3", loaded)
+			Expect(buf.String()).To(BeEmpty())
+		})
+
+		it("does not log a deprecation warning when only other buildpacks are configured", func() {
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), "{'composer': {'version': 2.0.0}}")
+
+			loaded, err := LoadBuildpackYAML(f.Detect.Application.Root)
+			Expect(err).ToNot(HaveOccurred())
+
+			buf := bytes.NewBuffer(nil)
+			logger := logger.Logger{Logger: bp.NewLogger(buf, buf)}
+			WarnBuildpackYAML(logger, "1.2.3", loaded)
+			Expect(buf.String()).To(BeEmpty())
+		})
+
+		when("there are unknown keys", func() {
+			it.Before(func() {
+				yaml := `{'composer': {'vendor_directory': 'vendor'}, 'php': {'web_server': 'nginx', 'webdir': 'public', 'workers': {'queue': {'command': 'php worker.php', 'replica': 2}}, 'colour': 'blue'}}`
+				test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "buildpack.yml"), yaml)
+			})
+
+			it("warns about each of them with suggestions", func() {
+				loaded, err := LoadBuildpackYAML(f.Detect.Application.Root)
+				Expect(err).ToNot(HaveOccurred())
+
+				buf := bytes.NewBuffer(nil)
+				logger := logger.Logger{Logger: bp.NewLogger(buf, buf)}
+				WarnBuildpackYAML(logger, "1.2.3", loaded)
+				Expect(buf.String()).To(ContainSubstring("buildpack.yml has an unknown key php.web_server, did you mean php.webserver?"))
+				Expect(buf.String()).To(ContainSubstring("buildpack.yml has an unknown key php.webdir, did you mean php.webdirectory?"))
+				Expect(buf.String()).To(ContainSubstring("buildpack.yml has an unknown key php.workers.queue.replica, did you mean php.workers.queue.replicas?"))
+				Expect(buf.String()).To(ContainSubstring("buildpack.yml has an unknown key php.colour"))
+				Expect(buf.String()).NotTo(ContainSubstring("php.colour, did you mean"))
+				Expect(buf.String()).NotTo(ContainSubstring("composer"))
+			})
+
+			it("ignores them when loading", func() {
+				_, err := LoadBuildpackYAML(f.Detect.Application.Root)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			when("strict config is enabled", func() {
+				it.Before(func() {
+					Expect(os.Setenv(StrictConfigEnv, "true")).To(Succeed())
+				})
+
+				it.After(func() {
+					Expect(os.Unsetenv(StrictConfigEnv)).To(Succeed())
+				})
+
+				it("fails listing all of them", func() {
+					_, err := LoadBuildpackYAML(f.Detect.Application.Root)
+					Expect(err).To(MatchError("invalid buildpack.yml, BP_PHP_STRICT_CONFIG is set: unknown key php.colour; " +
+						"unknown key php.web_server, did you mean php.webserver?; " +
+						"unknown key php.webdir, did you mean php.webdirectory?; " +
+						"unknown key php.workers.queue.replica, did you mean php.workers.queue.replicas?"))
+				})
+			})
+		})
+
 		when("the buildpack.yml is empty", func() {
 			it("does not log a warning against user-set buildpack.yml config", func() {
+				loaded, err := LoadBuildpackYAML(f.Detect.Application.Root)
+				Expect(err).ToNot(HaveOccurred())
+
 				buf := bytes.NewBuffer(nil)
 				logger := logger.Logger{Logger: bp.NewLogger(buf, buf)}
-				Expect(WarnBuildpackYAML(logger, "1.2.3", f.Detect.Application.Root)).To(Succeed())
+				WarnBuildpackYAML(logger, "1.2.3", loaded)
 				Expect(buf.String()).NotTo(MatchRegexp(`WARNING: Setting PHP configurations through buildpack.yml will be deprecated soon in buildpack v\d+.\d+.\d+.`))
 			})
 		})
 	})
 
+	when("Procfile", func() {
+		it("loads no processes without a Procfile", func() {
+			processes, err := LoadProcfile(f.Build.Application.Root)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(processes).To(BeEmpty())
+		})
+
+		it("loads the processes, skipping blank lines and comments", func() {
+			procfile := "# processes\nweb: php -S 0.0.0.0:$PORT -t public\n\nworker:php bin/console messenger:consume\n"
+			test.WriteFile(t, filepath.Join(f.Build.Application.Root, "Procfile"), procfile)
+
+			processes, err := LoadProcfile(f.Build.Application.Root)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(processes).To(Equal(Processes{
+				"web":    "php -S 0.0.0.0:$PORT -t public",
+				"worker": "php bin/console messenger:consume",
+			}))
+		})
+
+		it("rejects invalid lines", func() {
+			test.WriteFile(t, filepath.Join(f.Build.Application.Root, "Procfile"), "web: php app.php\njust a command\n")
+
+			_, err := LoadProcfile(f.Build.Application.Root)
+			Expect(err).To(MatchError(ContainSubstring(`invalid Procfile line 2 "just a command"`)))
+		})
+
+		it("rejects a process type declared in buildpack.yml as well", func() {
+			_, err := Processes{"worker": "php worker.php"}.Merge(Processes{"web": "php app.php", "worker": "php other.php"})
+			Expect(err).To(MatchError(`process type "worker" is declared in both php.processes and the Procfile`))
+		})
+	})
+
+	when("composer.json", func() {
+		it("loads a single bin", func() {
+			test.WriteFile(t, filepath.Join(f.Build.Application.Root, "composer.json"), `{"name": "some/app", "bin": "bin/app"}`)
+
+			composerJSON, err := LoadComposerJSON(f.Build.Application.Root)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(composerJSON.Bin).To(Equal(ComposerBin{"bin/app"}))
+		})
+
+		it("loads a list of bins", func() {
+			test.WriteFile(t, filepath.Join(f.Build.Application.Root, "composer.json"), `{"bin": ["bin/app", "bin/tool"]}`)
+
+			composerJSON, err := LoadComposerJSON(f.Build.Application.Root)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(composerJSON.Bin).To(Equal(ComposerBin{"bin/app", "bin/tool"}))
+		})
+
+		it("loads nothing without a composer.json", func() {
+			composerJSON, err := LoadComposerJSON(f.Build.Application.Root)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(composerJSON.Bin).To(BeEmpty())
+		})
+	})
+
+	when("composer.lock", func() {
+		it("loads the platform requirements", func() {
+			test.WriteFile(t, filepath.Join(f.Build.Application.Root, "composer.lock"), `{"packages": [], "platform": {"php": "^7.4", "ext-json": "*"}}`)
+
+			composerLock, err := LoadComposerLock(f.Build.Application.Root)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(composerLock.Platform).To(Equal(ComposerPlatform{"php": "^7.4", "ext-json": "*"}))
+		})
+
+		it("loads an empty list of platform requirements", func() {
+			test.WriteFile(t, filepath.Join(f.Build.Application.Root, "composer.lock"), `{"packages": [], "platform": []}`)
+
+			composerLock, err := LoadComposerLock(f.Build.Application.Root)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(composerLock.Platform).To(BeEmpty())
+		})
+	})
+
+	when("converting composer constraints", func() {
+		it("converts alternatives, ranges and stability flags", func() {
+			for constraint, expected := range map[string]string{
+				"^7.4":                 "^7.4",
+				"^7.2 | ^8.0":          "^7.2 || ^8.0",
+				">=7.2 <8.0@stable":    ">=7.2, <8.0",
+				">=7.2,<8.0 || ~8.1.0": ">=7.2, <8.0 || ~8.1.0",
+				"7.2 - 7.4":            "7.2 - 7.4",
+				"~7.2":                 ">=7.2, <8.0",
+				"~7.2 | ~8.0":          ">=7.2, <8.0 || >=8.0, <9.0",
+			} {
+				converted, err := ComposerConstraint(constraint)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(converted).To(Equal(expected), constraint)
+			}
+		})
+
+		it("allows later minor versions for a two segment tilde, like composer", func() {
+			converted, err := ComposerConstraint("~7.2")
+			Expect(err).ToNot(HaveOccurred())
+
+			constraint, err := semver.NewConstraint(converted)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(constraint.Check(semver.MustParse("7.4.0"))).To(BeTrue())
+			Expect(constraint.Check(semver.MustParse("8.0.0"))).To(BeFalse())
+		})
+
+		it("fails on a constraint which isn't semver", func() {
+			_, err := ComposerConstraint("dev-master")
+			Expect(err).To(MatchError(ContainSubstring(`unsupported constraint "dev-master"`)))
+		})
+	})
+
+	when("resolving security headers", func() {
+		it("sets no headers by default", func() {
+			Expect(SecurityHeaders{Profile: SecurityHeadersNone}.Resolve()).To(BeEmpty())
+		})
+
+		it("overrides and removes headers from the profile", func() {
+			headers := SecurityHeaders{
+				Profile: SecurityHeadersRecommended,
+				Headers: map[string]string{
+					"x-frame-options":           "DENY",
+					"Strict-Transport-Security": "",
+					"Permissions-Policy":        "camera=()",
+				},
+			}.Resolve()
+
+			Expect(headers).To(Equal([]Header{
+				{Name: "Permissions-Policy", Value: "camera=()"},
+				{Name: "Referrer-Policy", Value: "strict-origin-when-cross-origin"},
+				{Name: "X-Content-Type-Options", Value: "nosniff"},
+				{Name: "X-Frame-Options", Value: "DENY"},
+			}))
+		})
+	})
+
+	when("resolving the static asset policy", func() {
+		it("converts cache TTLs to seconds", func() {
+			Expect(CacheRule{TTL: "max"}.MaxAge()).To(Equal(315360000))
+			Expect(CacheRule{TTL: "90"}.MaxAge()).To(Equal(90))
+			Expect(CacheRule{TTL: "2h"}.MaxAge()).To(Equal(7200))
+			Expect(CacheRule{TTL: "1y"}.MaxAge()).To(Equal(31536000))
+		})
+
+		it("lists the compressible extensions of the gzip types", func() {
+			staticAssets := StaticAssets{GzipTypes: []string{"text/css", "application/json"}}
+			Expect(staticAssets.CompressibleExtensions()).To(Equal([]string{"css", "json", "map"}))
+		})
+	})
+
+	when("logging config", func() {
+		it("masks secret ini directives", func() {
+			buildpackYAML := BuildpackYAML{Config: Config{Ini: IniDirectives{
+				"newrelic.license": "some-license",
+				"memory_limit":     "256M",
+			}}}
+
+			result := buildpackYAML.String()
+			Expect(result).To(ContainSubstring("newrelic.license:" + Redacted))
+			Expect(result).To(ContainSubstring("memory_limit:256M"))
+			Expect(result).NotTo(ContainSubstring("some-license"))
+			Expect(buildpackYAML.Config.Ini["newrelic.license"]).To(Equal("some-license"))
+		})
+
+		it("only treats names with a secret word as secrets", func() {
+			for _, name := range []string{"newrelic.license", "license_key", "licenseKey", "password", "sess_sasl_password", "api-key", "AWS_ACCESS_KEY", "auth_token", "headers"} {
+				Expect(IsSecret(name)).To(BeTrue(), name)
+			}
+
+			for _, name := range []string{"keys_zone", "cache_key", "primary_key", "keyboard", "session.use_only_cookies", "expose_headers", "max_input_vars"} {
+				Expect(IsSecret(name)).To(BeFalse(), name)
+			}
+		})
+
+		it("formats buildpack.yml config", func() {
+			buildpackYAML := BuildpackYAML{Config: Config{WebServer: Nginx}}
+			Expect(buildpackYAML.String()).To(ContainSubstring("nginx"))
+		})
+	})
+
 	when("checking for a web app", func() {
 		it("defaults `php.webdir` to `htdocs`", func() {
 			Expect(PickWebDir(BuildpackYAML{})).To(Equal("htdocs"))
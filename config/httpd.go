@@ -41,6 +41,9 @@ LoadModule rewrite_module modules/mod_rewrite.so
 LoadModule filter_module modules/mod_filter.so
 LoadModule deflate_module modules/mod_deflate.so
 LoadModule headers_module modules/mod_headers.so
+{{- if .RequestID.Enabled}}
+LoadModule unique_id_module modules/mod_unique_id.so
+{{- end}}
 
 # Secure Directory Permissions
 <Directory />
@@ -71,14 +74,54 @@ LoadModule headers_module modules/mod_headers.so
     TypesConfig conf/mime.types
     AddType application/x-compress .Z
     AddType application/x-gzip .gz .tgz
+    AddType application/wasm .wasm
 </IfModule>
 
 # Deflate Support
 <IfModule filter_module>
     <IfModule deflate_module>
-        AddOutputFilterByType DEFLATE text/html text/plain text/xml text/css text/javascript application/javascript
+{{- if .StaticAssets.GzipTypes}}
+        AddOutputFilterByType DEFLATE {{.StaticAssets.GzipTypeList}}
+{{- end}}
     </IfModule>
 </IfModule>
+{{- if .StaticAssets.Precompressed}}
+
+# Serve precompressed .br & .gz sidecar files, when they exist & the client accepts them
+# InheritDownBefore runs these rules ahead of an app's .htaccess, which would otherwise replace them with its own RewriteEngine On
+<Directory "{{.AppRoot}}/{{.WebDirectory}}">
+    RewriteEngine On
+    RewriteOptions InheritDownBefore
+    RewriteCond "%{HTTP:Accept-Encoding}" "br"
+    RewriteCond "%{REQUEST_FILENAME}\.br" -s
+    RewriteRule "^(.+\.(?:{{.StaticAssets.CompressiblePattern}}))$" "$1.br" [QSA,L]
+    RewriteCond "%{HTTP:Accept-Encoding}" "gzip"
+    RewriteCond "%{REQUEST_FILENAME}\.gz" -s
+    RewriteRule "^(.+\.(?:{{.StaticAssets.CompressiblePattern}}))$" "$1.gz" [QSA,L]
+{{- range .StaticAssets.CompressibleTypes}}
+    RewriteRule "\.{{.Extension}}\.(?:br|gz)$" "-" [T={{.MimeType}},E=no-gzip:1]
+{{- end}}
+
+    <FilesMatch "\.(?:{{.StaticAssets.CompressiblePattern}})\.br$">
+        Header set Content-Encoding br
+        Header append Vary Accept-Encoding
+    </FilesMatch>
+    <FilesMatch "\.(?:{{.StaticAssets.CompressiblePattern}})\.gz$">
+        Header set Content-Encoding gzip
+        Header append Vary Accept-Encoding
+    </FilesMatch>
+</Directory>
+{{- end}}
+{{- if .StaticAssets.CacheRules}}
+
+# Some basic cache-control for static files to be sent to the browser, including precompressed sidecars
+{{- range .StaticAssets.CacheRules}}
+<FilesMatch "(?i)\.(?:{{.Pattern}})(?:\.br|\.gz)?$">
+    Header set Cache-Control "max-age={{.MaxAge}}, public, must-revalidate, proxy-revalidate"
+    Header set Pragma public
+</FilesMatch>
+{{- end}}
+{{- end}}
 
 # Log everything to STDOUT/STDERR & log CF specific info
 ErrorLog "/proc/self/fd/2"
@@ -86,11 +129,14 @@ LogLevel info
 <IfModule log_config_module>
     LogFormat "%a %l %u %t \"%r\" %>s %b \"%{Referer}i\" \"%{User-Agent}i\"" combined
     LogFormat "%a %l %u %t \"%r\" %>s %b" common
-    LogFormat "%a %l %u %t \"%r\" %>s %b vcap_request_id=%{X-Vcap-Request-Id}i peer_addr=%{c}a" extended
+    LogFormat "%a %l %u %t \"%r\" %>s %b vcap_request_id=%{X-Vcap-Request-Id}i peer_addr=%{c}a{{if .RequestID.Enabled}} request_id={{.RequestIDFormat}}{{end}}{{if .LogTraceIDs}} trace_id=%{TRACE_ID}e{{end}}" extended
+    # request time is in milliseconds, httpd does not record the time spent waiting for php-fpm separately
+    # httpd escapes control & non-ASCII bytes as \xhh, which is not valid JSON, unlike nginx's escape=json
+    LogFormat "{\"time\":\"%{%Y-%m-%dT%H:%M:%S%z}t\",\"remote_addr\":\"%a\",\"forwarded_for\":\"{{.ClientIPHeaderFormat}}\",\"request_id\":\"{{.RequestIDFormat}}\",{{if .LogTraceIDs}}\"trace_id\":\"%{TRACE_ID}e\",{{end}}\"method\":\"%m\",\"uri\":\"%U%q\",\"protocol\":\"%H\",\"status\":%>s,\"body_bytes_sent\":%B,\"request_time_ms\":%{ms}T,\"referer\":\"%{Referer}i\",\"user_agent\":\"%{User-Agent}i\"}" json
     <IfModule logio_module>
       LogFormat "%a %l %u %t \"%r\" %>s %b \"%{Referer}i\" \"%{User-Agent}i\" %I %O" combinedio
     </IfModule>
-    CustomLog "/proc/self/fd/1" extended
+    CustomLog "/proc/self/fd/1" {{.AccessLogFormat}}
 </IfModule>
 
 # configure event MPM
@@ -104,7 +150,7 @@ LogLevel info
 </IfModule>
 
 # Defaults
-Timeout 60
+Timeout {{.ProxyTimeout}}
 KeepAlive On
 MaxKeepAliveRequests 100
 KeepAliveTimeout 5
@@ -117,26 +163,42 @@ HostnameLookups Off
 EnableMMAP Off
 EnableSendfile On
 RequestReadTimeout header=20-40,MinRate=500 body=20,MinRate=500
+LimitRequestBody {{.LimitRequestBody}}
+
+{{- if .Proxy.TrustedRanges}}
 
 #
 # Adjust IP Address based on header set by proxy
 #
-RemoteIpHeader x-forwarded-for
-RemoteIpInternalProxy 10.0.0.0/8 172.16.0.0/12 192.168.0.0/16
+RemoteIpHeader {{.Proxy.ClientIPHeader}}
+RemoteIpInternalProxy{{range .Proxy.TrustedRanges}} {{.}}{{end}}
+{{- end}}
 
 #
 # Set HTTPS environment variable if we came in over secure
 #  channel.
-SetEnvIf x-forwarded-proto https HTTPS=on
+SetEnvIf {{.Proxy.ProtoHeader}} https HTTPS=on
+{{- if .Proxy.Forwarded}}
+SetEnvIf Forwarded proto="?https HTTPS=on
+{{- end}}
+{{- if .LogTraceIDs}}
+
+#
+# Log the Datadog trace ID sent by the caller, in the decimal form Datadog correlates logs with
+SetEnvIf x-datadog-trace-id "^([0-9]+)$" TRACE_ID=$1
+{{- end}}
 
 {{if not .DisableHTTPSRedirect }}
 #
 # If not HTTPS, forward to HTTPS
 #
 RewriteEngine On
-RewriteCond %{HTTP:X-Forwarded-Proto} !=""
+RewriteCond %{HTTP:{{.Proxy.ProtoHeader}}}{{if .Proxy.Forwarded}}%{HTTP:Forwarded}{{end}} !=""
 RewriteCond %{HTTPS} !=on
-RewriteCond %{HTTP:X-Forwarded-Proto} !https [NC]
+RewriteCond %{HTTP:{{.Proxy.ProtoHeader}}} !https [NC]
+{{- if .Proxy.Forwarded}}
+RewriteCond %{HTTP:Forwarded} !proto="?https [NC]
+{{- end}}
 RewriteRule ^ https://%{HTTP_HOST}%{REQUEST_URI} [L,R=301,NE]
 {{end}}
 
@@ -151,7 +213,7 @@ Define fcgi-listener fcgi://{{.FpmSocket}}{{.AppRoot}}/{{.WebDirectory}}
     # correctly and everything breaks.
 
     # NOTE: Setting retry to avoid cached HTTP 503 (See https://www.pivotaltracker.com/story/show/103840940)
-    ProxySet disablereuse=On retry=0
+    ProxySet disablereuse=On retry=0 timeout={{.ProxyTimeout}}
 </Proxy>
 
 <Directory "{{.AppRoot}}/{{.WebDirectory}}">
@@ -163,6 +225,16 @@ Define fcgi-listener fcgi://{{.FpmSocket}}{{.AppRoot}}/{{.WebDirectory}}
 </Directory>
 
 RequestHeader unset Proxy early
-
+{{- if .RequestID.Enabled}}
+
+# Generate a request ID when the client did not send one, PHP receives it like any other header
+RequestHeader setifempty {{.RequestID.Header}} "%{UNIQUE_ID}e"
+{{- end}}
+{{if .SecurityHeaders}}
+# Security headers
+{{- range .SecurityHeaders}}
+Header always set {{.Name}} {{printf "%q" .Value}}
+{{- end}}
+{{end}}
 IncludeOptional "{{.AppRoot}}/.httpd.conf.d/*.conf"
 `
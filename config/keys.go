@@ -0,0 +1,204 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// StrictConfigEnv fails the build, instead of warning, when buildpack.yml has unknown keys under `php`
+const StrictConfigEnv = "BP_PHP_STRICT_CONFIG"
+
+// UnknownKey is a key under `php` in buildpack.yml which is not a configuration option
+type UnknownKey struct {
+	Path       string
+	Suggestion string
+}
+
+func (u UnknownKey) String() string {
+	if u.Suggestion == "" {
+		return fmt.Sprintf("unknown key %s", u.Path)
+	}
+	return fmt.Sprintf("unknown key %s, did you mean %s?", u.Path, u.Suggestion)
+}
+
+// isStrictConfig checks if StrictConfigEnv is set to a true value
+func isStrictConfig() bool {
+	value := strings.ToLower(os.Getenv(StrictConfigEnv))
+	return value == "true" || value == "1" || value == "yes"
+}
+
+// unknownKeysError lists all of the unknown keys, for when StrictConfigEnv is set
+func unknownKeysError(unknownKeys []UnknownKey) error {
+	var messages []string
+	for _, unknownKey := range unknownKeys {
+		messages = append(messages, unknownKey.String())
+	}
+
+	return fmt.Errorf("invalid buildpack.yml, %s is set: %s", StrictConfigEnv, strings.Join(messages, "; "))
+}
+
+// parseKeys lists the keys explicitly set under `php` in the contents of buildpack.yml, as dotted paths like `php.webserver`,
+// and the keys which are not configuration options, sorted by path. Other top level keys are left alone, they configure other buildpacks.
+func parseKeys(contents []byte) (map[string]bool, []UnknownKey, error) {
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(contents, &document); err != nil {
+		return nil, nil, err
+	}
+
+	setKeys := map[string]bool{}
+	var unknownKeys []UnknownKey
+
+	if php, ok := document["php"]; ok {
+		setKeys["php"] = true
+		walkKeys("php", php, reflect.TypeOf(Config{}), setKeys, &unknownKeys)
+	}
+
+	sort.Slice(unknownKeys, func(i, j int) bool {
+		return unknownKeys[i].Path < unknownKeys[j].Path
+	})
+
+	return setKeys, unknownKeys, nil
+}
+
+// walkKeys compares the keys of value with the yaml fields of t, recursing into nested structs & maps of structs
+func walkKeys(path string, value interface{}, t reflect.Type, setKeys map[string]bool, unknownKeys *[]UnknownKey) {
+	values, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Map:
+		for key, nested := range values {
+			nestedPath := fmt.Sprintf("%s.%v", path, key)
+			setKeys[nestedPath] = true
+
+			if t.Elem().Kind() == reflect.Struct {
+				walkKeys(nestedPath, nested, t.Elem(), setKeys, unknownKeys)
+			}
+		}
+
+	case reflect.Struct:
+		fields := yamlFields(t)
+
+		var names []string
+		for name := range fields {
+			names = append(names, name)
+		}
+
+		for key, nested := range values {
+			name := fmt.Sprintf("%v", key)
+			if field, ok := fields[name]; ok {
+				setKeys[path+"."+name] = true
+				walkKeys(path+"."+name, nested, field, setKeys, unknownKeys)
+				continue
+			}
+
+			unknownKey := UnknownKey{Path: path + "." + name}
+			if suggestion := suggestKey(name, names); suggestion != "" {
+				unknownKey.Suggestion = path + "." + suggestion
+			}
+			*unknownKeys = append(*unknownKeys, unknownKey)
+		}
+	}
+}
+
+// yamlFields maps the yaml keys of the fields of struct type t to their types
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// suggestKey picks the known key closest to key, ignoring case & separators, or nothing when none are close
+func suggestKey(key string, known []string) string {
+	normalize := strings.NewReplacer("_", "", "-", "").Replace
+	normalized := normalize(strings.ToLower(key))
+
+	best, bestDistance := "", 3
+	for _, candidate := range known {
+		normalizedCandidate := normalize(candidate)
+
+		distance := levenshtein(normalized, normalizedCandidate)
+		if len(normalized) >= 3 && (strings.HasPrefix(normalizedCandidate, normalized) || strings.HasPrefix(normalized, normalizedCandidate)) {
+			distance = 1
+		}
+
+		if distance < bestDistance || (distance == bestDistance && candidate < best) {
+			best, bestDistance = candidate, distance
+		}
+	}
+
+	return best
+}
+
+// levenshtein is the number of single character edits to change a into b
+func levenshtein(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+func min(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+	return result
+}
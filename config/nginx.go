@@ -52,8 +52,12 @@ http {
         image/webp                            webp;
 
         application/font-woff                 woff;
+        font/woff2                            woff2;
+        font/ttf                              ttf;
+        font/otf                              otf;
         application/java-archive              jar war ear;
         application/json                      json;
+        application/wasm                      wasm;
         application/mac-binhex40              hqx;
         application/msword                    doc;
         application/pdf                       pdf;
@@ -116,22 +120,48 @@ http {
     sendfile           on;
     keepalive_timeout  65;
     gzip               on;
+{{- if .StaticAssets.GzipTypes}}
+    gzip_types         {{.GzipTypes}};
+{{- end}}
+{{- if .StaticAssets.Precompressed}}
+    gzip_static        on;
+{{- end}}
     port_in_redirect   off;
     root               {{.AppRoot}}/{{.WebDirectory}};
     index              index.php index.html;
     server_tokens      off;
-
+{{if .RequestID.Enabled}}
+    # use the request ID sent by the client, or generate one
+    map {{.RequestIDHeaderVariable}} $app_request_id {
+        ""          $request_id;
+        default     {{.RequestIDHeaderVariable}};
+    }
+{{end}}{{if .LogTraceIDs}}
+    # log the Datadog trace ID sent by the caller, in the decimal form Datadog correlates logs with
+    map $http_x_datadog_trace_id $trace_id {
+        "~^[0-9]+$"     $http_x_datadog_trace_id;
+        default         "";
+    }
+{{end}}
     log_format common '$remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent';
-    log_format extended '$remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent vcap_request_id=$http_x_vcap_request_id';
-    access_log  /dev/stdout  extended;
-
+    log_format extended '$remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent vcap_request_id=$http_x_vcap_request_id{{if .RequestID.Enabled}} request_id={{.RequestIDVariable}}{{end}}{{if .LogTraceIDs}} trace_id=$trace_id{{end}}';
+    log_format json escape=json '{"time":"$time_iso8601","remote_addr":"$remote_addr","forwarded_for":"{{.ClientIPHeaderVariable}}","request_id":"{{.RequestIDVariable}}",{{if .LogTraceIDs}}"trace_id":"$trace_id",{{end}}"method":"$request_method","uri":"$request_uri","protocol":"$server_protocol","status":$status,"body_bytes_sent":$body_bytes_sent,"request_time":$request_time,"upstream_response_time":"$upstream_response_time","referer":"$http_referer","user_agent":"$http_user_agent"}';
+    access_log  /dev/stdout  {{.AccessLogFormat}};
+{{if .Proxy.Forwarded}}
+    # prefer the proto from a RFC 7239 Forwarded header, falling back to {{.Proxy.ProtoHeader}}
+    map $http_forwarded $forwarded_proto {
+        default                  {{.ProtoHeaderVariable}};
+        "~*proto=\"?https\b"     https;
+        "~*proto=\"?http\b"      http;
+    }
+{{end}}
     # set $https only when SSL is actually used.
-    map $http_x_forwarded_proto $proxy_https {
+    map {{.ProtoVariable}} $proxy_https {
         https on;
     }
 
     # setup the scheme to use on redirects
-    map $http_x_forwarded_proto $redirect_scheme {
+    map {{.ProtoVariable}} $redirect_scheme {
         default http;
         http http;
         https https;
@@ -139,7 +169,7 @@ http {
 
 {{if not .DisableHTTPSRedirect }}
     # map conditions for redirect
-    map $http_x_forwarded_proto $redirect_to_https {
+    map {{.ProtoVariable}} $redirect_to_https {
         default no;
         http yes;
         https  no;
@@ -153,14 +183,23 @@ http {
     server {
         listen       {{"{{"}}env "PORT"{{"}}"}}  default_server;
         server_name localhost;
-
+{{if .SecurityHeaders}}
+        # Security headers, these must be repeated in any location using add_header
+{{- range .SecurityHeaders}}
+        add_header      {{.Name}} {{printf "%q" .Value}} always;
+{{- end}}
+{{end}}
         fastcgi_temp_path      /tmp/nginx_fastcgi 1 2;
         client_body_temp_path  /tmp/nginx_client_body 1 2;
+        client_max_body_size   {{.ClientMaxBodySize}};
         proxy_temp_path        /tmp/nginx_proxy 1 2;
-
-        real_ip_header         x-forwarded-for;
-        set_real_ip_from       10.0.0.0/8;
+{{if .Proxy.TrustedRanges}}
+        real_ip_header         {{.Proxy.ClientIPHeader}};
+{{- range .Proxy.TrustedRanges}}
+        set_real_ip_from       {{.}};
+{{- end}}
         real_ip_recursive      on;
+{{- end}}
 
 {{if not .DisableHTTPSRedirect }}
         # forward http to https
@@ -182,11 +221,16 @@ http {
         }
 
         # Some basic cache-control for static files to be sent to the browser
-        location ~* \.(?:ico|css|js|gif|jpeg|jpg|png)$ {
-            expires         max;
+{{- range .StaticAssets.CacheRules}}
+        location ~* \.(?:{{.Pattern}})$ {
+            expires         {{.TTL}};
             add_header      Pragma public;
             add_header      Cache-Control "public, must-revalidate, proxy-revalidate";
+{{- range $.SecurityHeaders}}
+            add_header      {{.Name}} {{printf "%q" .Value}} always;
+{{- end}}
         }
+{{- end}}
 
         location ~* \.php$ {
             try_files $uri =404;
@@ -212,8 +256,12 @@ http {
             fastcgi_param  SERVER_PORT        $server_port;
             fastcgi_param  SERVER_NAME        $host;
             fastcgi_param HTTP_PROXY "";
+{{- if .RequestID.Enabled}}
+            fastcgi_param  {{.RequestIDParam}}  {{.RequestIDVariable}};
+{{- end}}
 
             fastcgi_param   SCRIPT_FILENAME $document_root$fastcgi_script_name;
+            fastcgi_read_timeout  {{.FastCGIReadTimeout}}s;
             fastcgi_pass    php_fpm;
         }
 
@@ -456,7 +456,7 @@ pm.max_spare_servers = 3
 ; does not stop script execution for some reason. A value of '0' means 'off'.
 ; Available units: s(econds)(default), m(inutes), h(ours), or d(ays)
 ; Default Value: 0
-;request_terminate_timeout = 0
+request_terminate_timeout = {{.RequestTerminateTimeout}}
 	
 ; Set open file descriptor rlimit.
 ; Default Value: system defined value
@@ -0,0 +1,55 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+// PhpIniDirectivesTemplate is the template string for the ini file holding the `php.ini` directives of `buildpack.yml`
+const PhpIniDirectivesTemplate = `; Generated from php.ini in buildpack.yml
+{{ range $name, $value := .Quoted -}}
+{{ $name }} = {{ $value }}
+{{ end -}}
+`
+
+// PhpIniEnvProfile is a profile.d script, which writes the `PHP_INI_<directive>` environment variables set at launch
+// to an ini file & adds its directory to `PHP_INI_SCAN_DIR`, so that it's read after all other ini files.
+// `__` in the name of a variable stands for a `.` in the directive, e.g. `PHP_INI_date__timezone`. Values are quoted
+// like QuoteIniValue does.
+const PhpIniEnvProfile = `php_ini_env_dir="${TMPDIR:-/tmp}/php-ini-env.d"
+php_ini_expression='^[[:upper:][:digit:]_ |&~^!()]+$'
+mkdir -p "${php_ini_env_dir}"
+: > "${php_ini_env_dir}/env.ini"
+
+while IFS='=' read -r php_ini_name php_ini_value; do
+  case "${php_ini_name}" in
+    PHP_INI_SCAN_DIR) ;;
+    PHP_INI_?*)
+      if [[ "${php_ini_name}" == "PHP_INI_max_execution_time" ]]; then
+        echo "PHP_INI_max_execution_time does not change the time httpd, nginx & php-fpm wait, which follow php.request_timeout of the build" >&2
+      elif [[ "${php_ini_name}" == "PHP_INI_post_max_size" || "${php_ini_name}" == "PHP_INI_upload_max_filesize" ]]; then
+        echo "${php_ini_name} does not change the largest request body httpd & nginx accept, which follows php.max_request_body of the build" >&2
+      fi
+      php_ini_name="${php_ini_name#PHP_INI_}"
+      if [[ ! "${php_ini_value}" =~ ${php_ini_expression} ]]; then
+        php_ini_value="\"$(printf '%s' "${php_ini_value}" | sed -e 's/[\\"$]/\\&/g')\""
+      fi
+      printf '%s = %s\n' "${php_ini_name//__/.}" "${php_ini_value}" >> "${php_ini_env_dir}/env.ini"
+      ;;
+  esac
+done < <(env)
+
+export PHP_INI_SCAN_DIR="${PHP_INI_SCAN_DIR}:${php_ini_env_dir}"
+unset php_ini_env_dir php_ini_expression php_ini_name php_ini_value
+`
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+// PhpRouterTemplate is the template string for the router script of PHP's built-in Web Server
+const PhpRouterTemplate = `<?php
+// Router script for PHP's built-in Web Server, generated by the PHP Web Buildpack
+
+{{range .SecurityHeaders -}}
+header({{.PhpString}});
+{{end}}
+$path = rawurldecode((string) parse_url($_SERVER['REQUEST_URI'], PHP_URL_PATH));
+
+// deny hidden files & directories, like .env or .git
+foreach (explode('/', $path) as $segment) {
+    if ($segment !== '' && $segment[0] === '.') {
+        http_response_code(404);
+        return true;
+    }
+}
+{{if .Router}}
+// hand the request over to the application's router script
+return require {{.RouterString}};
+{{- else}}
+// serve existing files & directories with an index as-is
+$file = $_SERVER['DOCUMENT_ROOT'] . $path;
+{{- if .SecurityHeaders}}
+
+// the built-in server drops the headers set above when the router declines a request, so files are served here
+foreach (['', '/index.php', '/index.html'] as $index) {
+    if (!is_file($file . $index)) {
+        continue;
+    }
+
+    $documentRoot = realpath($_SERVER['DOCUMENT_ROOT']);
+    $file = realpath($file . $index);
+    if (strpos($file, $documentRoot . '/') !== 0) {
+        http_response_code(404);
+        return true;
+    }
+
+    $extension = strtolower(pathinfo($file, PATHINFO_EXTENSION));
+    if ($extension === 'php') {
+        $_SERVER['SCRIPT_NAME'] = substr($file, strlen($documentRoot));
+        $_SERVER['PHP_SELF'] = $_SERVER['SCRIPT_NAME'];
+        $_SERVER['SCRIPT_FILENAME'] = $file;
+        chdir(dirname($file));
+        return require $file;
+    }
+
+    $mimeTypes = [
+{{- range $extension, $mimeType := .MimeTypes}}
+        '{{$extension}}' => '{{$mimeType}}',
+{{- end}}
+    ];
+    header('Content-Type: ' . (isset($mimeTypes[$extension]) ? $mimeTypes[$extension] : 'application/octet-stream'));
+    header('Content-Length: ' . filesize($file));
+    readfile($file);
+    return true;
+}
+{{- else}}
+if (is_file($file) || is_file($file . '/index.php') || is_file($file . '/index.html')) {
+    return false;
+}
+{{- end}}
+
+// route everything else through the front controller
+$frontController = {{.FrontControllerString}};
+if (!is_file($_SERVER['DOCUMENT_ROOT'] . $frontController)) {
+    return false;
+}
+
+$_SERVER['SCRIPT_NAME'] = $frontController;
+$_SERVER['PHP_SELF'] = $frontController;
+$_SERVER['SCRIPT_FILENAME'] = $_SERVER['DOCUMENT_ROOT'] . $frontController;
+chdir(dirname($_SERVER['SCRIPT_FILENAME']));
+return require $_SERVER['SCRIPT_FILENAME'];
+{{- end}}
+`
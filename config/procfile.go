@@ -0,0 +1,82 @@
+/*
+ * Copyright 2018-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var procfileLinePattern = regexp.MustCompile(`^([A-Za-z0-9._-]+):\s*(.+)$`)
+
+// LoadProcfile reads the process types & commands from `Procfile` in the application root, if there is one
+func LoadProcfile(appRoot string) (Processes, error) {
+	file, err := os.Open(filepath.Join(appRoot, "Procfile"))
+	if os.IsNotExist(err) {
+		return Processes{}, nil
+	} else if err != nil {
+		return Processes{}, err
+	}
+	defer file.Close()
+
+	processes := Processes{}
+	scanner := bufio.NewScanner(file)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		matches := procfileLinePattern.FindStringSubmatch(line)
+		if matches == nil {
+			return Processes{}, fmt.Errorf("invalid Procfile line %d %q, must be `<type>: <command>`", number, line)
+		}
+
+		if _, exists := processes[matches[1]]; exists {
+			return Processes{}, fmt.Errorf("invalid Procfile line %d, process type %q is declared more than once", number, matches[1])
+		}
+
+		processes[matches[1]] = strings.TrimSpace(matches[2])
+	}
+
+	if err := scanner.Err(); err != nil {
+		return Processes{}, err
+	}
+
+	return processes, nil
+}
+
+// Merge combines the processes declared in `buildpack.yml` & the Procfile, a process type can only be declared in one of them
+func (p Processes) Merge(procfile Processes) (Processes, error) {
+	merged := Processes{}
+	for processType, command := range p {
+		merged[processType] = command
+	}
+
+	for processType, command := range procfile {
+		if _, exists := merged[processType]; exists {
+			return Processes{}, fmt.Errorf("process type %q is declared in both php.processes and the Procfile", processType)
+		}
+		merged[processType] = command
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,204 @@
+package features
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/cloudfoundry/libcfbuildpack/helper"
+	"github.com/cloudfoundry/libcfbuildpack/layers"
+	"github.com/cloudfoundry/libcfbuildpack/services"
+	"github.com/paketo-buildpacks/php-web/config"
+)
+
+// DatadogServiceName is the binding name, label or tag of a Datadog service
+const DatadogServiceName = "datadog"
+
+// DatadogFeature is used to enable the Datadog tracer, `ddtrace`
+type DatadogFeature struct {
+	datadogSupport    DatadogSupport
+	platformRoot      string
+	sessionHelperPath string
+	environ           []string
+}
+
+// NewDatadogFeature an object that Supports Datadog, environ are the build's `KEY=value` environment variables
+func NewDatadogFeature(featureConfig FeatureConfig, srvs services.Services, platformRoot, sessionHelperPath string, environ []string) DatadogFeature {
+	return DatadogFeature{
+		datadogSupport:    FromExistingDatadogSupport(featureConfig, srvs, ""),
+		platformRoot:      platformRoot,
+		sessionHelperPath: sessionHelperPath,
+		environ:           environ,
+	}
+}
+
+// Name of the feature
+func (d DatadogFeature) Name() string {
+	return "Datadog"
+}
+
+// DatadogLaunchScript is a profile.d script, which loads the tracer when it wasn't enabled for the build but a `DD_*`
+// variable is set at launch, unless tracing is turned off with `DD_TRACE_ENABLED`
+const DatadogLaunchScript = `#!/bin/bash
+case "$(echo "${DD_TRACE_ENABLED:-}" | tr '[:upper:]' '[:lower:]')" in
+  0|f|false) ;;
+  *)
+    if [[ -n "$(compgen -e DD_)" ]]; then
+      session_helper \
+        --binding-name %q \
+        --search-term %q \
+        --session-driver %q \
+        --platform-root %q \
+        --app-root %q
+    fi
+    ;;
+esac
+`
+
+// IsNeeded is always true, as the tracer can also be enabled by `DD_*` variables set at launch
+func (d DatadogFeature) IsNeeded() bool {
+	return true
+}
+
+// Enabled determines if a Datadog service is bound, or any `DD_*` variable is set for the build, unless tracing is
+// turned off with `DD_TRACE_ENABLED`
+func (d DatadogFeature) Enabled() bool {
+	if enabled, err := strconv.ParseBool(d.getenv("DD_TRACE_ENABLED")); err == nil && !enabled {
+		return false
+	}
+
+	if _, found := d.datadogSupport.FindService(); found {
+		return true
+	}
+
+	for _, variable := range d.environ {
+		if strings.HasPrefix(variable, "DD_") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ServiceTags are the values of Datadog's unified service tags, `DD_SERVICE`, `DD_ENV` & `DD_VERSION`, taken from the
+// build's environment, then the bound service, and for `DD_SERVICE` the package name in composer.json. Tags without a
+// value are left out.
+func (d DatadogFeature) ServiceTags() (map[string]string, error) {
+	creds, _ := d.datadogSupport.FindService()
+
+	tags := map[string]string{}
+	for name, key := range map[string]string{"DD_SERVICE": "service", "DD_ENV": "env", "DD_VERSION": "version"} {
+		if value := d.getenv(name); value != "" {
+			tags[name] = value
+		} else if value := credentialString(creds, key); value != "" {
+			tags[name] = value
+		}
+	}
+
+	if _, ok := tags["DD_SERVICE"]; !ok {
+		composerJSON, err := config.LoadComposerJSON(d.datadogSupport.appRoot)
+		if err != nil {
+			return nil, err
+		}
+
+		if composerJSON.Name != "" {
+			tags["DD_SERVICE"] = composerJSON.Name
+		}
+	}
+
+	return tags, nil
+}
+
+// EnableFeature sets the service tags & loads the tracer when the app is launched. When the tracer isn't enabled for
+// the build, it's only loaded if a `DD_*` variable is set at launch, and no tags are set, as they'd always enable it.
+func (d DatadogFeature) EnableFeature(_ layers.Layers, layer layers.Layer) error {
+	if err := helper.CopyFile(d.sessionHelperPath, filepath.Join(layer.Root, "bin", "session_helper")); err != nil {
+		return err
+	}
+
+	script := DatadogLaunchScript
+	if d.Enabled() {
+		tags, err := d.ServiceTags()
+		if err != nil {
+			return err
+		}
+
+		// defaults, so that the tags can still be changed when launching the app
+		for name, value := range tags {
+			if err := layer.DefaultLaunchEnv(name, value); err != nil {
+				return err
+			}
+		}
+
+		script = SessionHelperScript
+	}
+
+	return layer.WriteProfile(
+		"0_datadog.sh",
+		script,
+		DatadogServiceName,
+		DatadogServiceName,
+		DatadogServiceName,
+		d.platformRoot,
+		d.datadogSupport.appRoot,
+	)
+}
+
+func (d DatadogFeature) getenv(name string) string {
+	for _, variable := range d.environ {
+		if strings.HasPrefix(variable, name+"=") {
+			return strings.TrimPrefix(variable, name+"=")
+		}
+	}
+	return ""
+}
+
+// DatadogSupport provides functionality to load the Datadog tracer & connect it to the agent of a bound service
+type DatadogSupport struct {
+	extensionSupport
+}
+
+func FromExistingDatadogSupport(featureConfig FeatureConfig, srvs services.Services, extensionDir string) DatadogSupport {
+	return DatadogSupport{newExtensionSupport(featureConfig.App.Root, extensionDir, srvs, DatadogServiceName)}
+}
+
+func NewDatadogSupport(platformRoot, appRoot, extensionDir string) (DatadogSupport, error) {
+	support, err := loadExtensionSupport(platformRoot, appRoot, extensionDir, DatadogServiceName)
+	return DatadogSupport{support}, err
+}
+
+// ConfigureService writes the tracer's ini file, with the agent's address from a bound service. `DD_*` variables set
+// at launch take precedence over it.
+func (d DatadogSupport) ConfigureService() error {
+	exists, err := helper.FileExists(filepath.Join(d.extensionDir, "ddtrace.so"))
+	if err != nil {
+		return err
+	} else if !exists {
+		return fmt.Errorf("the Datadog tracer is not installed in %q, it is not enabled", d.extensionDir)
+	}
+
+	buf := bytes.Buffer{}
+	buf.WriteString("extension=ddtrace.so\n")
+
+	if creds, found := d.FindService(); found {
+		if url := credentialString(creds, "trace_agent_url", "url"); url != "" {
+			buf.WriteString(fmt.Sprintf("datadog.trace.agent_url=%s\n", config.QuoteIniValue(url)))
+		}
+
+		if host := credentialString(creds, "agent_host", "host", "hostname"); host != "" {
+			buf.WriteString(fmt.Sprintf("datadog.agent_host=%s\n", config.QuoteIniValue(host)))
+		}
+
+		if port, found := creds["trace_agent_port"].(float64); found {
+			buf.WriteString(fmt.Sprintf("datadog.trace.agent_port=%d\n", int(port)))
+		} else if port := credentialString(creds, "trace_agent_port"); port != "" {
+			buf.WriteString(fmt.Sprintf("datadog.trace.agent_port=%s\n", config.QuoteIniValue(port)))
+		}
+	}
+
+	// written at launch like the other service files, so it must only be readable by the launch user
+	filename := filepath.Join(d.appRoot, ".php.ini.d", "datadog.ini")
+	return writeCredentialsFile(filename, buf.Bytes())
+}
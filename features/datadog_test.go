@@ -0,0 +1,214 @@
+package features_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudfoundry/libcfbuildpack/layers"
+
+	"github.com/cloudfoundry/libcfbuildpack/services"
+	"github.com/paketo-buildpacks/php-web/config"
+	"github.com/paketo-buildpacks/php-web/features"
+
+	"github.com/cloudfoundry/libcfbuildpack/test"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitDatadog(t *testing.T) {
+	spec.Run(t, "Datadog", testDatadog, spec.Report(report.Terminal{}))
+}
+
+func testDatadog(t *testing.T, when spec.G, it spec.S) {
+
+	var (
+		factory *test.BuildFactory
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		factory = test.NewBuildFactory(t)
+	})
+
+	datadogFeatureFactory := func(environ ...string) features.DatadogFeature {
+		return features.NewDatadogFeature(
+			features.FeatureConfig{
+				BpYAML:   config.BuildpackYAML{},
+				App:      factory.Build.Application,
+				IsWebApp: true,
+			},
+			factory.Build.Services,
+			factory.Build.Platform.Root,
+			filepath.Join(factory.Build.Buildpack.Root, "bin", "session_helper"),
+			environ,
+		)
+	}
+
+	when("Enabled", func() {
+		it("is always needed, as DD_ variables can be set at launch", func() {
+			Expect(datadogFeatureFactory("PATH=/usr/bin").IsNeeded()).To(BeTrue())
+		})
+
+		it("is detected when a datadog service is bound", func() {
+			factory.AddService("apm", services.Credentials{"host": "datadog-agent"}, "datadog")
+			Expect(datadogFeatureFactory("PATH=/usr/bin").Enabled()).To(BeTrue())
+		})
+
+		it("is detected when a DD_ variable is set", func() {
+			Expect(datadogFeatureFactory("PATH=/usr/bin", "DD_ENV=prod").Enabled()).To(BeTrue())
+		})
+
+		it("is not detected when tracing is turned off", func() {
+			factory.AddService("datadog", services.Credentials{"host": "datadog-agent"})
+			Expect(datadogFeatureFactory("DD_ENV=prod", "DD_TRACE_ENABLED=false").Enabled()).To(BeFalse())
+			Expect(datadogFeatureFactory("DD_TRACE_ENABLED=0").Enabled()).To(BeFalse())
+			Expect(datadogFeatureFactory("DD_TRACE_ENABLED=true").Enabled()).To(BeTrue())
+		})
+
+		it("is not detected otherwise", func() {
+			Expect(datadogFeatureFactory("PATH=/usr/bin", "ADD_ENV=prod").Enabled()).To(BeFalse())
+		})
+	})
+
+	when("ServiceTags", func() {
+		it("prefers the environment, then the bound service, then composer.json", func() {
+			test.WriteFile(t, filepath.Join(factory.Build.Application.Root, "composer.json"), `{"name": "acme/shop"}`)
+			factory.AddService("datadog", services.Credentials{"env": "staging", "version": "1.2.3"})
+
+			tags, err := datadogFeatureFactory("DD_ENV=prod").ServiceTags()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tags).To(Equal(map[string]string{"DD_SERVICE": "acme/shop", "DD_ENV": "prod", "DD_VERSION": "1.2.3"}))
+		})
+
+		it("leaves out tags without a value", func() {
+			d := datadogFeatureFactory("DD_SERVICE=shop")
+
+			tags, err := d.ServiceTags()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tags).To(Equal(map[string]string{"DD_SERVICE": "shop"}))
+		})
+	})
+
+	when("EnableFeature", func() {
+		var layer layers.Layer
+
+		it.Before(func() {
+			layer = factory.Build.Layers.Layer("test")
+			test.WriteFile(t, filepath.Join(factory.Build.Buildpack.Root, "bin", "session_helper"), "session-helper-contents")
+		})
+
+		it("sets the service tags & runs the session_helper", func() {
+			d := datadogFeatureFactory("DD_SERVICE=shop", "DD_VERSION=1.2.3")
+			Expect(d.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
+			Expect(layer).To(test.HaveDefaultLaunchEnvironment("DD_SERVICE", "shop"))
+			Expect(layer).To(test.HaveDefaultLaunchEnvironment("DD_VERSION", "1.2.3"))
+			Expect(filepath.Join(layer.Root, "bin", "session_helper")).To(test.HaveContent("session-helper-contents"))
+			Expect(layer).To(test.HaveProfile("0_datadog.sh", `#!/bin/bash
+session_helper \
+  --binding-name "datadog" \
+  --search-term "datadog" \
+  --session-driver "datadog" \
+  --platform-root %q \
+  --app-root %q
+`,
+				factory.Build.Platform.Root,
+				factory.Build.Application.Root,
+			))
+		})
+
+		it("only loads the tracer when a DD_ variable is set at launch, if it's not enabled for the build", func() {
+			d := datadogFeatureFactory("PATH=/usr/bin")
+			Expect(d.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+			Expect(filepath.Join(layer.Root, "bin", "session_helper")).To(test.HaveContent("session-helper-contents"))
+
+			binDir := filepath.Join(factory.Build.Platform.Root, "fake-bin")
+			called := filepath.Join(binDir, "called")
+			test.WriteFileWithPerm(t, filepath.Join(binDir, "session_helper"), 0755, "#!/bin/bash\necho \"$@\" > %q\n", called)
+
+			runProfile := func(env ...string) {
+				cmd := exec.Command("bash", filepath.Join(layer.Root, "profile.d", "0_datadog.sh"))
+				cmd.Env = append([]string{"PATH=" + binDir + ":" + os.Getenv("PATH")}, env...)
+				output, err := cmd.CombinedOutput()
+				Expect(err).ToNot(HaveOccurred(), string(output))
+			}
+
+			runProfile()
+			Expect(called).NotTo(BeAnExistingFile())
+
+			runProfile("DD_AGENT_HOST=agent", "DD_TRACE_ENABLED=false")
+			Expect(called).NotTo(BeAnExistingFile())
+
+			runProfile("DD_AGENT_HOST=agent")
+			Expect(called).To(test.HaveContent(fmt.Sprintf(
+				"--binding-name datadog --search-term datadog --session-driver datadog --platform-root %s --app-root %s\n",
+				factory.Build.Platform.Root,
+				factory.Build.Application.Root,
+			)))
+		})
+
+		it("doesn't set the service tags when the tracer isn't enabled for the build", func() {
+			test.WriteFile(t, filepath.Join(factory.Build.Application.Root, "composer.json"), `{"name": "acme/shop"}`)
+
+			d := datadogFeatureFactory("PATH=/usr/bin")
+			Expect(d.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+			Expect(filepath.Join(layer.Root, "env.launch", "DD_SERVICE.default")).NotTo(BeAnExistingFile())
+		})
+	})
+
+	when("DatadogSupport", func() {
+		var (
+			extensionDir string
+			iniPath      string
+		)
+
+		datadogSupport := func() features.DatadogSupport {
+			return features.FromExistingDatadogSupport(
+				features.FeatureConfig{
+					App: factory.Build.Application,
+				},
+				factory.Build.Services,
+				extensionDir,
+			)
+		}
+
+		it.Before(func() {
+			extensionDir = filepath.Join(factory.Build.Platform.Root, "extensions")
+			iniPath = filepath.Join(factory.Build.Application.Root, ".php.ini.d", "datadog.ini")
+			test.WriteFile(t, filepath.Join(extensionDir, "ddtrace.so"), "")
+		})
+
+		it("loads the tracer without a bound service", func() {
+			Expect(datadogSupport().ConfigureService()).To(Succeed())
+			Expect(iniPath).To(test.HaveContent("extension=ddtrace.so\n"))
+		})
+
+		it("connects to the agent of a bound service", func() {
+			factory.AddService("datadog", services.Credentials{
+				"host":             "datadog-agent",
+				"trace_agent_port": float64(8126), // simulate how JSON handles numbers as float64
+			})
+
+			Expect(datadogSupport().ConfigureService()).To(Succeed())
+
+			contents, err := ioutil.ReadFile(iniPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal(`extension=ddtrace.so
+datadog.agent_host="datadog-agent"
+datadog.trace.agent_port=8126
+`))
+		})
+
+		it("fails when the tracer is not installed", func() {
+			extensionDir = filepath.Join(factory.Build.Platform.Root, "missing")
+
+			Expect(datadogSupport().ConfigureService()).To(MatchError(ContainSubstring("the Datadog tracer is not installed")))
+			Expect(iniPath).NotTo(BeAnExistingFile())
+		})
+	})
+}
@@ -12,6 +12,12 @@ type FeatureConfig struct {
 	App      application.Application
 	IsWebApp bool
 	Logger   logger.Logger
+
+	// Processes are declared by the user, through `php.processes` & the Procfile, a `web` process replaces the generated one
+	Processes config.Processes
+
+	// LogTraceIDs adds the trace ID sent by the caller to the httpd & nginx access logs
+	LogTraceIDs bool
 }
 
 // Feature is used to add additional features to the CNB
@@ -31,4 +37,7 @@ type Feature interface {
 // SessionConfigurer is used to generate configuration for session_helper
 type SessionConfigurer interface {
 	ConfigureService() error
+
+	// Redact masks service credentials so that message is safe to log
+	Redact(message string) string
 }
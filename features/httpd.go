@@ -14,16 +14,18 @@ import (
 )
 
 type HttpdFeature struct {
-	bpYAML   config.BuildpackYAML
-	app      application.Application
-	isWebApp bool
+	bpYAML      config.BuildpackYAML
+	app         application.Application
+	isWebApp    bool
+	logTraceIDs bool
 }
 
 func NewHttpdFeature(featureConfig FeatureConfig) HttpdFeature {
 	return HttpdFeature{
-		bpYAML:   featureConfig.BpYAML,
-		app:      featureConfig.App,
-		isWebApp: featureConfig.IsWebApp,
+		bpYAML:      featureConfig.BpYAML,
+		app:         featureConfig.App,
+		isWebApp:    featureConfig.IsWebApp,
+		logTraceIDs: featureConfig.LogTraceIDs,
 	}
 }
 
@@ -51,6 +53,14 @@ func (p HttpdFeature) writeConfig() error {
 		WebDirectory:         p.bpYAML.Config.WebDirectory,
 		FpmSocket:            "127.0.0.1:9000",
 		DisableHTTPSRedirect: !p.bpYAML.Config.EnableHTTPSRedirect,
+		Proxy:                p.bpYAML.Config.Proxy,
+		SecurityHeaders:      p.bpYAML.Config.SecurityHeaders.Resolve(),
+		AccessLogFormat:      p.bpYAML.Config.AccessLogFormat,
+		RequestID:            p.bpYAML.Config.RequestID,
+		LogTraceIDs:          p.logTraceIDs,
+		StaticAssets:         p.bpYAML.Config.StaticAssets,
+		MaxRequestBody:       p.bpYAML.Config.MaxRequestBody,
+		RequestTimeout:       p.bpYAML.Config.RequestTimeout,
 	}
 	template := config.HttpdConfTemplate
 	confPath := filepath.Join(p.app.Root, "httpd.conf")
@@ -64,12 +74,20 @@ func (p HttpdFeature) updateProcs(layer layers.Layer) error {
 	}
 
 	procsYaml := filepath.Join(layer.Root, "procs.yml")
+	proc := procmgr.Proc{
+		Command: "httpd",
+		Args:    []string{"-f", filepath.Join(p.app.Root, "httpd.conf"), "-k", "start", "-DFOREGROUND"},
+	}
+
+	// SIGUSR1 is a graceful restart, which reloads the configuration without aborting open connections
+	if p.bpYAML.Config.LiveReload {
+		proc.Watch = []string{filepath.Join(p.app.Root, ".httpd.conf.d")}
+		proc.ReloadSignal = "USR1"
+	}
+
 	procs := procmgr.Procs{
 		Processes: map[string]procmgr.Proc{
-			"httpd": procmgr.Proc{
-				Command: "httpd",
-				Args:    []string{"-f", filepath.Join(p.app.Root, "httpd.conf"), "-k", "start", "-DFOREGROUND"},
-			},
+			"httpd": proc,
 		},
 	}
 
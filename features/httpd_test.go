@@ -117,5 +117,52 @@ func testHttpd(t *testing.T, when spec.G, it spec.S) {
 				},
 			}))
 		})
+
+		it("runs the precompressed sidecar rules ahead of an app .htaccess", func() {
+			htaccess := filepath.Join(factory.Build.Application.Root, "some-dir", ".htaccess")
+			test.WriteFile(t, htaccess, "%s", "RewriteEngine On\nRewriteCond %{REQUEST_FILENAME} !-f\nRewriteRule ^ index.php [L]\n")
+
+			p = features.NewHttpdFeature(
+				features.FeatureConfig{
+					BpYAML: config.BuildpackYAML{Config: config.Config{
+						WebServer:    config.ApacheHttpd,
+						WebDirectory: "some-dir",
+						StaticAssets: config.StaticAssets{Precompressed: true},
+					}},
+					App:      factory.Build.Application,
+					IsWebApp: true,
+				},
+			)
+
+			layer := factory.Build.Layers.Layer("layer-1")
+			Expect(p.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
+			buf, err := ioutil.ReadFile(filepath.Join(factory.Build.Application.Root, "httpd.conf"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(buf)).To(ContainSubstring("<Directory \"" + filepath.Join(factory.Build.Application.Root, "some-dir") + "\">\n    RewriteEngine On\n    RewriteOptions InheritDownBefore\n"))
+			Expect(htaccess).To(BeARegularFile())
+		})
+
+		it("gracefully restarts httpd when its configuration changes, with live reload", func() {
+			p = features.NewHttpdFeature(
+				features.FeatureConfig{
+					BpYAML: config.BuildpackYAML{Config: config.Config{
+						WebServer:    config.ApacheHttpd,
+						WebDirectory: "some-dir",
+						LiveReload:   true,
+					}},
+					App:      factory.Build.Application,
+					IsWebApp: true,
+				},
+			)
+
+			layer := factory.Build.Layers.Layer("layer-1")
+			Expect(p.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
+			procs, err := procmgr.ReadProcs(filepath.Join(layer.Root, "procs.yml"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(procs.Processes["httpd"].Watch).To(Equal([]string{filepath.Join(factory.Build.Application.Root, ".httpd.conf.d")}))
+			Expect(procs.Processes["httpd"].ReloadSignal).To(Equal("USR1"))
+		})
 	})
 }
@@ -3,14 +3,8 @@ package features
 import (
 	"bytes"
 	"fmt"
-	"io/ioutil"
-	"os"
 	"path/filepath"
 
-	"github.com/buildpack/libbuildpack/logger"
-	"github.com/buildpack/libbuildpack/platform"
-	lbservices "github.com/buildpack/libbuildpack/services"
-
 	"github.com/cloudfoundry/libcfbuildpack/helper"
 	"github.com/cloudfoundry/libcfbuildpack/layers"
 	"github.com/cloudfoundry/libcfbuildpack/services"
@@ -82,24 +76,14 @@ func FromExistingMemcachedSessionSupport(featureConfig FeatureConfig, srvs servi
 }
 
 func NewMemcachedSessionSupport(platformRoot, appRoot string) (MemcachedSessionSupport, error) {
-	logger, err := logger.DefaultLogger(platformRoot)
-	if err != nil {
-		return MemcachedSessionSupport{}, err
-	}
-
-	platform, err := platform.DefaultPlatform(platformRoot, logger)
-	if err != nil {
-		return MemcachedSessionSupport{}, err
-	}
-
-	defaultServices, err := lbservices.DefaultServices(platform, logger)
+	srvs, err := loadServices(platformRoot)
 	if err != nil {
 		return MemcachedSessionSupport{}, err
 	}
 
 	return MemcachedSessionSupport{
 		appRoot:    appRoot,
-		services:   services.Services{Services: defaultServices},
+		services:   srvs,
 		serviceKey: "",
 	}, nil
 }
@@ -122,13 +106,15 @@ func (s MemcachedSessionSupport) ConfigureService() error {
 	buf.WriteString(fmt.Sprintf("memcached.sess_sasl_username=%q\n", username))
 	buf.WriteString(fmt.Sprintf("memcached.sess_sasl_password=%q\n", password))
 
-	// don't use helper.WriteFile because it will mess up the URLencoded values
+	// contains credentials, so it must only be readable by the launch user
 	filename := filepath.Join(s.appRoot, ".php.ini.d", "memcached-sessions.ini")
-	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
-		return err
-	}
+	return writeCredentialsFile(filename, buf.Bytes())
+}
 
-	return ioutil.WriteFile(filename, buf.Bytes(), 0644)
+// Redact masks any credentials from the bound service which appear in message
+func (s MemcachedSessionSupport) Redact(message string) string {
+	creds, _ := s.FindService()
+	return redactCredentials(message, creds)
 }
 
 func (s MemcachedSessionSupport) FindService() (services.Credentials, bool) {
@@ -304,6 +304,19 @@ session_helper \
 					Expect(string(contents)).To(ContainSubstring("memcached.sess_sasl_username=\"user-1\""))
 					Expect(string(contents)).To(ContainSubstring(`memcached.sess_sasl_password="fake!@#$%\"^&*()-={]}[?><,./;':"`))
 				})
+
+				it("is only readable by the launch user", func() {
+					Expect(sessionSupport.ConfigureService()).To(Succeed())
+
+					info, err := os.Stat(filepath.Join(factory.Build.Application.Root, ".php.ini.d", "memcached-sessions.ini"))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+				})
+
+				it("redacts the credentials from messages", func() {
+					message := sessionSupport.Redact(`memcached.sess_sasl_password="fake!@#$%\"^&*()-={]}[?><,./;':"`)
+					Expect(message).To(Equal(fmt.Sprintf("memcached.sess_sasl_password=%s", config.Redacted)))
+				})
 			})
 		})
 	})
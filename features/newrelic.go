@@ -0,0 +1,146 @@
+package features
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cloudfoundry/libcfbuildpack/helper"
+	"github.com/cloudfoundry/libcfbuildpack/layers"
+	"github.com/cloudfoundry/libcfbuildpack/services"
+	"github.com/paketo-buildpacks/php-web/config"
+)
+
+// NewRelicServiceName is the binding name, label or tag of a New Relic service
+const NewRelicServiceName = "newrelic"
+
+// NewRelicFeature is used to enable the New Relic PHP agent
+type NewRelicFeature struct {
+	newRelicSupport   NewRelicSupport
+	platformRoot      string
+	sessionHelperPath string
+}
+
+// NewNewRelicFeature an object that Supports New Relic
+func NewNewRelicFeature(featureConfig FeatureConfig, srvs services.Services, platformRoot, sessionHelperPath string) NewRelicFeature {
+	return NewRelicFeature{
+		newRelicSupport:   FromExistingNewRelicSupport(featureConfig, srvs, ""),
+		platformRoot:      platformRoot,
+		sessionHelperPath: sessionHelperPath,
+	}
+}
+
+// Name of the feature
+func (n NewRelicFeature) Name() string {
+	return "New Relic"
+}
+
+// IsNeeded determines if a New Relic service is bound to this app
+func (n NewRelicFeature) IsNeeded() bool {
+	_, found := n.newRelicSupport.FindService()
+	return found
+}
+
+// EnableFeature will configure the New Relic agent when the app is launched
+func (n NewRelicFeature) EnableFeature(_ layers.Layers, layer layers.Layer) error {
+	err := helper.CopyFile(n.sessionHelperPath, filepath.Join(layer.Root, "bin", "session_helper"))
+	if err != nil {
+		return err
+	}
+
+	return layer.WriteProfile(
+		"0_newrelic.sh",
+		SessionHelperScript,
+		NewRelicServiceName,
+		NewRelicServiceName,
+		NewRelicServiceName,
+		n.platformRoot,
+		n.newRelicSupport.appRoot,
+	)
+}
+
+// NewRelicSupport provides functionality to locate a New Relic service and configure the PHP agent for it
+type NewRelicSupport struct {
+	extensionSupport
+}
+
+func FromExistingNewRelicSupport(featureConfig FeatureConfig, srvs services.Services, extensionDir string) NewRelicSupport {
+	return NewRelicSupport{newExtensionSupport(featureConfig.App.Root, extensionDir, srvs, NewRelicServiceName)}
+}
+
+func NewNewRelicSupport(platformRoot, appRoot, extensionDir string) (NewRelicSupport, error) {
+	support, err := loadExtensionSupport(platformRoot, appRoot, extensionDir, NewRelicServiceName)
+	return NewRelicSupport{support}, err
+}
+
+// ConfigureService writes the agent's ini file, it fails when no service is bound, the service has no license key, or
+// the agent isn't installed in the extension directory
+func (n NewRelicSupport) ConfigureService() error {
+	creds, found := n.FindService()
+	if !found {
+		return fmt.Errorf("no %s service is bound", NewRelicServiceName)
+	}
+
+	license := credentialString(creds, "license_key", "licenseKey", "license")
+	if license == "" {
+		return fmt.Errorf("the %s service has no license_key", NewRelicServiceName)
+	}
+
+	exists, err := helper.FileExists(filepath.Join(n.extensionDir, "newrelic.so"))
+	if err != nil {
+		return err
+	} else if !exists {
+		return fmt.Errorf("the New Relic agent is not installed in %q, it is not enabled", n.extensionDir)
+	}
+
+	buf := bytes.Buffer{}
+	buf.WriteString("extension=newrelic.so\n")
+	buf.WriteString(fmt.Sprintf("newrelic.license=%s\n", config.QuoteIniValue(license)))
+
+	if appName := credentialString(creds, "app_name", "appName", "appname"); appName != "" {
+		buf.WriteString(fmt.Sprintf("newrelic.appname=%s\n", config.QuoteIniValue(appName)))
+	}
+
+	if labels := n.labels(creds); labels != "" {
+		buf.WriteString(fmt.Sprintf("newrelic.labels=%s\n", config.QuoteIniValue(labels)))
+	}
+
+	// the agent & its daemon log to the container's output
+	buf.WriteString("newrelic.logfile=\"/dev/stderr\"\n")
+	buf.WriteString("newrelic.daemon.logfile=\"/dev/stderr\"\n")
+
+	// the agent starts a daemon itself, unless one is running elsewhere
+	if address := credentialString(creds, "daemon_address", "daemonAddress"); address != "" {
+		buf.WriteString(fmt.Sprintf("newrelic.daemon.address=%s\n", config.QuoteIniValue(address)))
+		buf.WriteString("newrelic.daemon.dont_launch=3\n")
+	}
+
+	distributedTracing := !strings.EqualFold(credentialString(creds, "distributed_tracing", "distributedTracing"), "false")
+	if enabled, ok := creds["distributed_tracing"].(bool); ok {
+		distributedTracing = enabled
+	}
+	buf.WriteString(fmt.Sprintf("newrelic.distributed_tracing_enabled=%t\n", distributedTracing))
+
+	// contains the license key, so it must only be readable by the launch user
+	filename := filepath.Join(n.appRoot, ".php.ini.d", "newrelic.ini")
+	return writeCredentialsFile(filename, buf.Bytes())
+}
+
+// labels are either given as the agent's `name:value;name:value` string, or as an object
+func (n NewRelicSupport) labels(creds services.Credentials) string {
+	switch labels := creds["labels"].(type) {
+	case string:
+		return labels
+	case map[string]interface{}:
+		var pairs []string
+		for name, value := range labels {
+			pairs = append(pairs, fmt.Sprintf("%s:%v", name, value))
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ";")
+	default:
+		return ""
+	}
+}
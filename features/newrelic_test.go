@@ -0,0 +1,207 @@
+package features_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudfoundry/libcfbuildpack/layers"
+
+	"github.com/cloudfoundry/libcfbuildpack/services"
+	"github.com/paketo-buildpacks/php-web/config"
+	"github.com/paketo-buildpacks/php-web/features"
+
+	"github.com/cloudfoundry/libcfbuildpack/test"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitNewRelic(t *testing.T) {
+	spec.Run(t, "NewRelic", testNewRelic, spec.Report(report.Terminal{}))
+}
+
+func testNewRelic(t *testing.T, when spec.G, it spec.S) {
+
+	var (
+		factory *test.BuildFactory
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		factory = test.NewBuildFactory(t)
+	})
+
+	newRelicFeatureFactory := func(svcs services.Services) features.NewRelicFeature {
+		return features.NewNewRelicFeature(
+			features.FeatureConfig{
+				BpYAML:   config.BuildpackYAML{},
+				App:      factory.Build.Application,
+				IsWebApp: true,
+			},
+			svcs,
+			factory.Build.Platform.Root,
+			filepath.Join(factory.Build.Buildpack.Root, "bin", "session_helper"),
+		)
+	}
+
+	when("IsNeeded", func() {
+		it("is detected when name is `newrelic`", func() {
+			factory.AddService("newrelic", services.Credentials{"license_key": "fake-license"})
+			n := newRelicFeatureFactory(factory.Build.Services)
+
+			Expect(n.IsNeeded()).To(BeTrue())
+		})
+
+		it("is detected when name is not `newrelic` but there is a `newrelic` tag", func() {
+			factory.AddService("apm", services.Credentials{"license_key": "fake-license"}, "newrelic")
+			n := newRelicFeatureFactory(factory.Build.Services)
+
+			Expect(n.IsNeeded()).To(BeTrue())
+		})
+
+		it("is not detected without a newrelic service", func() {
+			factory.AddService("redis", services.Credentials{"password": "fake"})
+			n := newRelicFeatureFactory(factory.Build.Services)
+
+			Expect(n.IsNeeded()).To(BeFalse())
+		})
+	})
+
+	when("EnableFeature", func() {
+		var layer layers.Layer
+
+		it.Before(func() {
+			layer = factory.Build.Layers.Layer("test")
+			test.WriteFile(t, filepath.Join(factory.Build.Buildpack.Root, "bin", "session_helper"), "session-helper-contents")
+		})
+
+		it("writes a profile.d script to run the session_helper", func() {
+			n := newRelicFeatureFactory(factory.Build.Services)
+			Expect(n.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
+			Expect(filepath.Join(layer.Root, "bin", "session_helper")).To(test.HaveContent("session-helper-contents"))
+
+			script, err := ioutil.ReadFile(filepath.Join(layer.Root, "profile.d", "0_newrelic.sh"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(script)).To(Equal(
+				fmt.Sprintf(`#!/bin/bash
+session_helper \
+  --binding-name "newrelic" \
+  --search-term "newrelic" \
+  --session-driver "newrelic" \
+  --platform-root %q \
+  --app-root %q
+`,
+					factory.Build.Platform.Root,
+					factory.Build.Application.Root,
+				),
+			))
+		})
+	})
+
+	when("NewRelicSupport", func() {
+		var (
+			extensionDir string
+			iniPath      string
+		)
+
+		newRelicSupport := func() features.NewRelicSupport {
+			return features.FromExistingNewRelicSupport(
+				features.FeatureConfig{
+					App: factory.Build.Application,
+				},
+				factory.Build.Services,
+				extensionDir,
+			)
+		}
+
+		it.Before(func() {
+			extensionDir = filepath.Join(factory.Build.Platform.Root, "extensions")
+			iniPath = filepath.Join(factory.Build.Application.Root, ".php.ini.d", "newrelic.ini")
+			test.WriteFile(t, filepath.Join(extensionDir, "newrelic.so"), "")
+		})
+
+		it("renders the agent, daemon & distributed tracing settings", func() {
+			factory.AddService("newrelic", services.Credentials{
+				"license_key": "fake-license",
+				"app_name":    "my-app",
+				"labels":      map[string]interface{}{"team": "web", "env": "prod"},
+			})
+
+			Expect(newRelicSupport().ConfigureService()).To(Succeed())
+
+			contents, err := ioutil.ReadFile(iniPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal(`extension=newrelic.so
+newrelic.license="fake-license"
+newrelic.appname="my-app"
+newrelic.labels="env:prod;team:web"
+newrelic.logfile="/dev/stderr"
+newrelic.daemon.logfile="/dev/stderr"
+newrelic.distributed_tracing_enabled=true
+`))
+
+			info, err := os.Stat(iniPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+		})
+
+		it("connects to an external daemon & disables distributed tracing", func() {
+			factory.AddService("newrelic", services.Credentials{
+				"licenseKey":          "fake-license",
+				"labels":              "team:web",
+				"daemon_address":      "newrelic-daemon:31339",
+				"distributed_tracing": false,
+			})
+
+			Expect(newRelicSupport().ConfigureService()).To(Succeed())
+
+			contents, err := ioutil.ReadFile(iniPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(ContainSubstring(`newrelic.labels="team:web"`))
+			Expect(string(contents)).To(ContainSubstring("newrelic.daemon.address=\"newrelic-daemon:31339\"\nnewrelic.daemon.dont_launch=3\n"))
+			Expect(string(contents)).To(ContainSubstring("newrelic.distributed_tracing_enabled=false"))
+			Expect(string(contents)).NotTo(ContainSubstring("newrelic.appname"))
+		})
+
+		it("quotes app names & labels for php.ini", func() {
+			factory.AddService("newrelic", services.Credentials{
+				"license_key": "fake-license",
+				"app_name":    `café "$HOME"`,
+				"labels":      `team:a\b`,
+			})
+
+			Expect(newRelicSupport().ConfigureService()).To(Succeed())
+
+			contents, err := ioutil.ReadFile(iniPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(ContainSubstring(`newrelic.appname="café \"\$HOME\""`))
+			Expect(string(contents)).To(ContainSubstring(`newrelic.labels="team:a\\b"`))
+		})
+
+		it("fails without a license key", func() {
+			factory.AddService("newrelic", services.Credentials{"app_name": "my-app"})
+
+			Expect(newRelicSupport().ConfigureService()).To(MatchError("the newrelic service has no license_key"))
+			Expect(iniPath).NotTo(BeAnExistingFile())
+		})
+
+		it("fails when the agent is not installed", func() {
+			factory.AddService("newrelic", services.Credentials{"license_key": "fake-license"})
+			Expect(os.Remove(filepath.Join(extensionDir, "newrelic.so"))).To(Succeed())
+
+			Expect(newRelicSupport().ConfigureService()).To(MatchError(ContainSubstring("the New Relic agent is not installed")))
+			Expect(iniPath).NotTo(BeAnExistingFile())
+		})
+
+		it("redacts the license key from messages", func() {
+			factory.AddService("newrelic", services.Credentials{"license": "fake-license"})
+
+			message := newRelicSupport().Redact(`newrelic.license="fake-license"`)
+			Expect(message).To(Equal(fmt.Sprintf("newrelic.license=%s", config.Redacted)))
+		})
+	})
+}
@@ -12,16 +12,18 @@ import (
 )
 
 type NginxFeature struct {
-	bpYAML   config.BuildpackYAML
-	app      application.Application
-	isWebApp bool
+	bpYAML      config.BuildpackYAML
+	app         application.Application
+	isWebApp    bool
+	logTraceIDs bool
 }
 
 func NewNginxFeature(featureConfig FeatureConfig) NginxFeature {
 	return NginxFeature{
-		bpYAML:   featureConfig.BpYAML,
-		app:      featureConfig.App,
-		isWebApp: featureConfig.IsWebApp,
+		bpYAML:      featureConfig.BpYAML,
+		app:         featureConfig.App,
+		isWebApp:    featureConfig.IsWebApp,
+		logTraceIDs: featureConfig.LogTraceIDs,
 	}
 }
 
@@ -47,6 +49,14 @@ func (p NginxFeature) writeConfig(currentLayer layers.Layer) error {
 		WebDirectory:         p.bpYAML.Config.WebDirectory,
 		FpmSocket:            filepath.Join(currentLayer.Root, "php-fpm.socket"),
 		DisableHTTPSRedirect: !p.bpYAML.Config.EnableHTTPSRedirect,
+		Proxy:                p.bpYAML.Config.Proxy,
+		SecurityHeaders:      p.bpYAML.Config.SecurityHeaders.Resolve(),
+		AccessLogFormat:      p.bpYAML.Config.AccessLogFormat,
+		RequestID:            p.bpYAML.Config.RequestID,
+		LogTraceIDs:          p.logTraceIDs,
+		StaticAssets:         p.bpYAML.Config.StaticAssets,
+		MaxRequestBody:       p.bpYAML.Config.MaxRequestBody,
+		RequestTimeout:       p.bpYAML.Config.RequestTimeout,
 	}
 	template := config.NginxConfTemplate
 	confPath := filepath.Join(p.app.Root, "nginx.conf")
@@ -60,12 +70,20 @@ func (p NginxFeature) updateProcs(layer layers.Layer) error {
 	}
 
 	procsYaml := filepath.Join(layer.Root, "procs.yml")
+	proc := procmgr.Proc{
+		Command: "nginx",
+		Args:    []string{"-p", p.app.Root, "-c", filepath.Join(p.app.Root, "nginx.conf")},
+	}
+
+	// SIGHUP reloads the configuration & gracefully replaces the workers
+	if p.bpYAML.Config.LiveReload {
+		proc.Watch = []string{filepath.Join(p.app.Root, ".nginx.conf.d")}
+		proc.ReloadSignal = "HUP"
+	}
+
 	procs := procmgr.Procs{
 		Processes: map[string]procmgr.Proc{
-			"nginx": procmgr.Proc{
-				Command: "nginx",
-				Args:    []string{"-p", p.app.Root, "-c", filepath.Join(p.app.Root, "nginx.conf")},
-			},
+			"nginx": proc,
 		},
 	}
 
@@ -113,5 +113,27 @@ func testNginx(t *testing.T, when spec.G, it spec.S) {
 			}))
 		})
 
+		it("reloads nginx when its configuration changes, with live reload", func() {
+			p = features.NewNginxFeature(
+				features.FeatureConfig{
+					BpYAML: config.BuildpackYAML{Config: config.Config{
+						WebServer:    config.Nginx,
+						WebDirectory: "some-dir",
+						LiveReload:   true,
+					}},
+					App:      factory.Build.Application,
+					IsWebApp: true,
+				},
+			)
+
+			layer := factory.Build.Layers.Layer("layer-1")
+			Expect(p.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
+			procs, err := procmgr.ReadProcs(filepath.Join(layer.Root, "procs.yml"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(procs.Processes["nginx"].Watch).To(Equal([]string{filepath.Join(factory.Build.Application.Root, ".nginx.conf.d")}))
+			Expect(procs.Processes["nginx"].ReloadSignal).To(Equal("HUP"))
+		})
+
 	})
 }
@@ -0,0 +1,137 @@
+package features
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+
+	"github.com/cloudfoundry/libcfbuildpack/helper"
+	"github.com/cloudfoundry/libcfbuildpack/layers"
+	"github.com/cloudfoundry/libcfbuildpack/services"
+	"github.com/paketo-buildpacks/php-web/config"
+)
+
+// OpenTelemetryServiceName is the binding name, label or tag of a service receiving OpenTelemetry data
+const OpenTelemetryServiceName = "opentelemetry"
+
+// OpenTelemetryFeature is used to trace requests with the `opentelemetry` extension
+type OpenTelemetryFeature struct {
+	bpYAML            config.BuildpackYAML
+	telemetrySupport  OpenTelemetrySupport
+	platformRoot      string
+	sessionHelperPath string
+}
+
+// NewOpenTelemetryFeature an object that Supports OpenTelemetry
+func NewOpenTelemetryFeature(featureConfig FeatureConfig, srvs services.Services, platformRoot, sessionHelperPath string) OpenTelemetryFeature {
+	return OpenTelemetryFeature{
+		bpYAML:            featureConfig.BpYAML,
+		telemetrySupport:  FromExistingOpenTelemetrySupport(featureConfig, srvs, ""),
+		platformRoot:      platformRoot,
+		sessionHelperPath: sessionHelperPath,
+	}
+}
+
+// Name of the feature
+func (o OpenTelemetryFeature) Name() string {
+	return "OpenTelemetry"
+}
+
+// IsNeeded determines if the app opted into tracing
+func (o OpenTelemetryFeature) IsNeeded() bool {
+	return o.bpYAML.Config.OpenTelemetry.Enabled
+}
+
+// EnableFeature sets the resource attributes & loads the extension when the app is launched
+func (o OpenTelemetryFeature) EnableFeature(_ layers.Layers, layer layers.Layer) error {
+	serviceName, err := o.serviceName()
+	if err != nil {
+		return err
+	}
+
+	// defaults, so that any `OTEL_*` variable set at launch takes precedence
+	if serviceName != "" {
+		if err := layer.DefaultLaunchEnv("OTEL_SERVICE_NAME", serviceName); err != nil {
+			return err
+		}
+	}
+
+	if err := layer.DefaultLaunchEnv("OTEL_PHP_AUTOLOAD_ENABLED", "true"); err != nil {
+		return err
+	}
+
+	if err := helper.CopyFile(o.sessionHelperPath, filepath.Join(layer.Root, "bin", "session_helper")); err != nil {
+		return err
+	}
+
+	return layer.WriteProfile(
+		"0_opentelemetry.sh",
+		SessionHelperScript,
+		OpenTelemetryServiceName,
+		OpenTelemetryServiceName,
+		OpenTelemetryServiceName,
+		o.platformRoot,
+		o.telemetrySupport.appRoot,
+	)
+}
+
+func (o OpenTelemetryFeature) serviceName() (string, error) {
+	if o.bpYAML.Config.OpenTelemetry.ServiceName != "" {
+		return o.bpYAML.Config.OpenTelemetry.ServiceName, nil
+	}
+
+	composerJSON, err := config.LoadComposerJSON(o.telemetrySupport.appRoot)
+	if err != nil {
+		return "", err
+	}
+
+	return composerJSON.Name, nil
+}
+
+// OpenTelemetrySupport provides functionality to load the `opentelemetry` extension & configure its exporter for a
+// bound service
+type OpenTelemetrySupport struct {
+	extensionSupport
+}
+
+func FromExistingOpenTelemetrySupport(featureConfig FeatureConfig, srvs services.Services, extensionDir string) OpenTelemetrySupport {
+	return OpenTelemetrySupport{newExtensionSupport(featureConfig.App.Root, extensionDir, srvs, OpenTelemetryServiceName)}
+}
+
+func NewOpenTelemetrySupport(platformRoot, appRoot, extensionDir string) (OpenTelemetrySupport, error) {
+	support, err := loadExtensionSupport(platformRoot, appRoot, extensionDir, OpenTelemetryServiceName)
+	return OpenTelemetrySupport{support}, err
+}
+
+// ConfigureService writes the extension's ini file, with the exporter settings of a bound service. The SDK reads
+// `OTEL_*` settings from the environment first, so variables set at launch take precedence over the binding.
+func (o OpenTelemetrySupport) ConfigureService() error {
+	exists, err := helper.FileExists(filepath.Join(o.extensionDir, "opentelemetry.so"))
+	if err != nil {
+		return err
+	} else if !exists {
+		return fmt.Errorf("the opentelemetry extension is not installed in %q, it is not enabled", o.extensionDir)
+	}
+
+	buf := bytes.Buffer{}
+	buf.WriteString("extension=opentelemetry.so\n")
+
+	if creds, found := o.FindService(); found {
+		for _, setting := range []struct {
+			name string
+			keys []string
+		}{
+			{"OTEL_EXPORTER_OTLP_ENDPOINT", []string{"endpoint", "otlp_endpoint"}},
+			{"OTEL_EXPORTER_OTLP_PROTOCOL", []string{"protocol", "otlp_protocol"}},
+			{"OTEL_EXPORTER_OTLP_HEADERS", []string{"headers", "otlp_headers"}},
+		} {
+			if value := credentialString(creds, setting.keys...); value != "" {
+				buf.WriteString(fmt.Sprintf("%s=%s\n", setting.name, config.QuoteIniValue(value)))
+			}
+		}
+	}
+
+	// the exporter headers usually carry an API key, so it must only be readable by the launch user
+	filename := filepath.Join(o.appRoot, ".php.ini.d", "opentelemetry.ini")
+	return writeCredentialsFile(filename, buf.Bytes())
+}
@@ -0,0 +1,166 @@
+package features_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudfoundry/libcfbuildpack/layers"
+
+	"github.com/cloudfoundry/libcfbuildpack/services"
+	"github.com/paketo-buildpacks/php-web/config"
+	"github.com/paketo-buildpacks/php-web/features"
+
+	"github.com/cloudfoundry/libcfbuildpack/test"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitOpenTelemetry(t *testing.T) {
+	spec.Run(t, "OpenTelemetry", testOpenTelemetry, spec.Report(report.Terminal{}))
+}
+
+func testOpenTelemetry(t *testing.T, when spec.G, it spec.S) {
+
+	var (
+		factory *test.BuildFactory
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		factory = test.NewBuildFactory(t)
+	})
+
+	openTelemetryFeatureFactory := func(openTelemetry config.OpenTelemetry) features.OpenTelemetryFeature {
+		return features.NewOpenTelemetryFeature(
+			features.FeatureConfig{
+				BpYAML:   config.BuildpackYAML{Config: config.Config{OpenTelemetry: openTelemetry}},
+				App:      factory.Build.Application,
+				IsWebApp: true,
+			},
+			factory.Build.Services,
+			factory.Build.Platform.Root,
+			filepath.Join(factory.Build.Buildpack.Root, "bin", "session_helper"),
+		)
+	}
+
+	when("IsNeeded", func() {
+		it("is needed when enabled", func() {
+			Expect(openTelemetryFeatureFactory(config.OpenTelemetry{Enabled: true}).IsNeeded()).To(BeTrue())
+		})
+
+		it("is not needed for a bound service alone", func() {
+			factory.AddService("opentelemetry", services.Credentials{"endpoint": "http://collector:4318"})
+			Expect(openTelemetryFeatureFactory(config.OpenTelemetry{}).IsNeeded()).To(BeFalse())
+		})
+	})
+
+	when("EnableFeature", func() {
+		var layer layers.Layer
+
+		it.Before(func() {
+			layer = factory.Build.Layers.Layer("test")
+			test.WriteFile(t, filepath.Join(factory.Build.Buildpack.Root, "bin", "session_helper"), "session-helper-contents")
+		})
+
+		it("names the service after the composer package & runs the session_helper", func() {
+			test.WriteFile(t, filepath.Join(factory.Build.Application.Root, "composer.json"), `{"name": "acme/shop"}`)
+
+			o := openTelemetryFeatureFactory(config.OpenTelemetry{Enabled: true})
+			Expect(o.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
+			Expect(layer).To(test.HaveDefaultLaunchEnvironment("OTEL_SERVICE_NAME", "acme/shop"))
+			Expect(layer).To(test.HaveDefaultLaunchEnvironment("OTEL_PHP_AUTOLOAD_ENABLED", "true"))
+			Expect(filepath.Join(layer.Root, "bin", "session_helper")).To(test.HaveContent("session-helper-contents"))
+			Expect(layer).To(test.HaveProfile("0_opentelemetry.sh", `#!/bin/bash
+session_helper \
+  --binding-name "opentelemetry" \
+  --search-term "opentelemetry" \
+  --session-driver "opentelemetry" \
+  --platform-root %q \
+  --app-root %q
+`,
+				factory.Build.Platform.Root,
+				factory.Build.Application.Root,
+			))
+		})
+
+		it("uses the configured service name", func() {
+			test.WriteFile(t, filepath.Join(factory.Build.Application.Root, "composer.json"), `{"name": "acme/shop"}`)
+
+			o := openTelemetryFeatureFactory(config.OpenTelemetry{Enabled: true, ServiceName: "storefront"})
+			Expect(o.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
+			Expect(layer).To(test.HaveDefaultLaunchEnvironment("OTEL_SERVICE_NAME", "storefront"))
+		})
+
+		it("leaves the service name to the SDK without a composer package name", func() {
+			o := openTelemetryFeatureFactory(config.OpenTelemetry{Enabled: true})
+			Expect(o.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
+			Expect(filepath.Join(layer.Root, "env.launch", "OTEL_SERVICE_NAME.default")).NotTo(BeAnExistingFile())
+		})
+	})
+
+	when("OpenTelemetrySupport", func() {
+		var (
+			extensionDir string
+			iniPath      string
+		)
+
+		openTelemetrySupport := func() features.OpenTelemetrySupport {
+			return features.FromExistingOpenTelemetrySupport(
+				features.FeatureConfig{
+					App: factory.Build.Application,
+				},
+				factory.Build.Services,
+				extensionDir,
+			)
+		}
+
+		it.Before(func() {
+			extensionDir = filepath.Join(factory.Build.Platform.Root, "extensions")
+			iniPath = filepath.Join(factory.Build.Application.Root, ".php.ini.d", "opentelemetry.ini")
+			test.WriteFile(t, filepath.Join(extensionDir, "opentelemetry.so"), "")
+		})
+
+		it("loads the extension without a bound service", func() {
+			Expect(openTelemetrySupport().ConfigureService()).To(Succeed())
+			Expect(iniPath).To(test.HaveContent("extension=opentelemetry.so\n"))
+		})
+
+		it("configures the exporter of a bound service", func() {
+			factory.AddService("collector", services.Credentials{
+				"endpoint": "https://otlp.example.com:4318",
+				"protocol": "http/protobuf",
+				"headers":  "api-key=fake-key",
+			}, "opentelemetry")
+
+			Expect(openTelemetrySupport().ConfigureService()).To(Succeed())
+
+			contents, err := ioutil.ReadFile(iniPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal(`extension=opentelemetry.so
+OTEL_EXPORTER_OTLP_ENDPOINT="https://otlp.example.com:4318"
+OTEL_EXPORTER_OTLP_PROTOCOL="http/protobuf"
+OTEL_EXPORTER_OTLP_HEADERS="api-key=fake-key"
+`))
+		})
+
+		it("fails when the extension is not installed", func() {
+			extensionDir = filepath.Join(factory.Build.Platform.Root, "missing")
+
+			Expect(openTelemetrySupport().ConfigureService()).To(MatchError(ContainSubstring("the opentelemetry extension is not installed")))
+			Expect(iniPath).NotTo(BeAnExistingFile())
+		})
+
+		it("redacts the exporter headers from messages", func() {
+			factory.AddService("opentelemetry", services.Credentials{"headers": "api-key=fake-key"})
+
+			message := openTelemetrySupport().Redact(`OTEL_EXPORTER_OTLP_HEADERS="api-key=fake-key"`)
+			Expect(message).To(Equal(fmt.Sprintf("OTEL_EXPORTER_OTLP_HEADERS=%s", config.Redacted)))
+		})
+	})
+}
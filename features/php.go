@@ -6,6 +6,7 @@ import (
 	"github.com/paketo-buildpacks/php-web/config"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 type PhpFeature struct {
@@ -37,15 +38,36 @@ func (p PhpFeature) EnableFeature(commonLayers layers.Layers, currentLayer layer
 		return err
 	}
 
-	return currentLayer.OverrideSharedEnv("PHP_INI_SCAN_DIR", filepath.Join(p.app.Root, ".php.ini.d"))
+	// ini files are read in order, so directives from buildpack.yml override `.php.ini.d` & are overridden at launch
+	scanDirs := []string{filepath.Join(p.app.Root, ".php.ini.d")}
+
+	if len(p.bpYAML.Config.Ini) > 0 {
+		iniDir := filepath.Join(currentLayer.Root, "etc", "php.ini.d")
+		if err := config.ProcessTemplateToFile(config.PhpIniDirectivesTemplate, filepath.Join(iniDir, "buildpack.ini"), p.bpYAML.Config.Ini); err != nil {
+			return err
+		}
+		scanDirs = append(scanDirs, iniDir)
+	}
+
+	if err := currentLayer.OverrideSharedEnv("PHP_INI_SCAN_DIR", strings.Join(scanDirs, string(os.PathListSeparator))); err != nil {
+		return err
+	}
+
+	return currentLayer.WriteProfile("php-ini-env.sh", "%s", config.PhpIniEnvProfile)
 }
 
 func (p PhpFeature) writePhpIni(layer layers.Layer) error {
 	phpIniCfg := config.PhpIniConfig{
-		AppRoot:      p.app.Root,
-		LibDirectory: p.bpYAML.Config.LibDirectory,
-		PhpHome:      os.Getenv("PHP_HOME"),
-		PhpAPI:       os.Getenv("PHP_API"),
+		AppRoot:         p.app.Root,
+		LibDirectory:    p.bpYAML.Config.LibDirectory,
+		PhpHome:         os.Getenv("PHP_HOME"),
+		PhpAPI:          os.Getenv("PHP_API"),
+		Profile:         p.bpYAML.Config.IniProfile,
+		ConfigDirectory: filepath.Join(layer.Root, "etc"),
+		MaxRequestBody:  p.bpYAML.Config.MaxRequestBody,
+		RequestTimeout:  p.bpYAML.Config.RequestTimeout,
+		LiveReload:      p.bpYAML.Config.LiveReload,
+		TempDirectory:   os.Getenv("TMPDIR"),
 	}
 	phpIniPath := filepath.Join(layer.Root, "etc", "php.ini")
 	return config.ProcessTemplateToFile(config.PhpIniTemplate, phpIniPath, phpIniCfg)
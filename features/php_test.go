@@ -6,7 +6,11 @@ import (
 	"github.com/paketo-buildpacks/php-web/features"
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
+	"io/ioutil"
+	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -57,8 +61,88 @@ func testPhp(t *testing.T, when spec.G, it spec.S) {
 			Expect(filepath.Join(layer.Root, "etc", "php.ini")).To(BeARegularFile())
 			Expect(layer).To(test.HaveOverrideSharedEnvironment("PHPRC", filepath.Join(layer.Root, "etc")))
 			Expect(layer).To(test.HaveOverrideSharedEnvironment("PHP_INI_SCAN_DIR", filepath.Join(factory.Build.Application.Root, ".php.ini.d")))
+			Expect(layer).To(test.HaveProfile("php-ini-env.sh", "%s", config.PhpIniEnvProfile))
+			Expect(filepath.Join(layer.Root, "etc", "php.ini.d")).NotTo(BeADirectory())
+		})
+
+		it("writes the ini directives of buildpack.yml & scans them after .php.ini.d", func() {
+			p = features.NewPhpFeature(
+				features.FeatureConfig{
+					BpYAML: config.BuildpackYAML{Config: config.Config{
+						Ini: config.IniDirectives{"memory_limit": "512M", "date.timezone": "Europe/Berlin", "error_reporting": "E_ALL & ~E_DEPRECATED"},
+					}},
+					App: factory.Build.Application,
+				},
+			)
 
+			layer := factory.Build.Layers.Layer("layer-1")
+			Expect(p.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
+			iniDir := filepath.Join(layer.Root, "etc", "php.ini.d")
+			Expect(filepath.Join(iniDir, "buildpack.ini")).To(test.HaveContent("; Generated from php.ini in buildpack.yml\ndate.timezone = \"Europe/Berlin\"\nerror_reporting = E_ALL & ~E_DEPRECATED\nmemory_limit = 512M\n"))
+			Expect(layer).To(test.HaveOverrideSharedEnvironment("PHP_INI_SCAN_DIR",
+				filepath.Join(factory.Build.Application.Root, ".php.ini.d")+":"+iniDir))
 		})
 
+		it("writes the PHP_INI_ variables set at launch to an ini file", func() {
+			layer := factory.Build.Layers.Layer("layer-1")
+			Expect(p.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
+			tmpDir, err := ioutil.TempDir("", "php-ini-env")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(tmpDir)
+
+			cmd := exec.Command("bash", filepath.Join(layer.Root, "profile.d", "php-ini-env.sh"))
+			cmd.Env = []string{
+				"PATH=" + os.Getenv("PATH"),
+				"TMPDIR=" + tmpDir,
+				"PHP_INI_memory_limit=512M",
+				"PHP_INI_date__timezone=Europe/Berlin",
+				`PHP_INI_user_agent=say "hi" to ${USER}`,
+			}
+			output, err := cmd.CombinedOutput()
+			Expect(err).ToNot(HaveOccurred(), string(output))
+
+			contents, err := ioutil.ReadFile(filepath.Join(tmpDir, "php-ini-env.d", "env.ini"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(strings.Split(strings.TrimSpace(string(contents)), "\n")).To(ConsistOf(
+				"memory_limit = 512M",
+				`date.timezone = "Europe/Berlin"`,
+				`user_agent = "say \"hi\" to \${USER}"`,
+			))
+		})
+
+		it("warns that PHP_INI_max_execution_time does not change the web server timeouts", func() {
+			layer := factory.Build.Layers.Layer("layer-1")
+			Expect(p.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
+			tmpDir, err := ioutil.TempDir("", "php-ini-env")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(tmpDir)
+
+			cmd := exec.Command("bash", filepath.Join(layer.Root, "profile.d", "php-ini-env.sh"))
+			cmd.Env = []string{"PATH=" + os.Getenv("PATH"), "TMPDIR=" + tmpDir, "PHP_INI_max_execution_time=60"}
+			output, err := cmd.CombinedOutput()
+			Expect(err).ToNot(HaveOccurred(), string(output))
+			Expect(string(output)).To(ContainSubstring("PHP_INI_max_execution_time does not change the time httpd, nginx & php-fpm wait"))
+
+			Expect(filepath.Join(tmpDir, "php-ini-env.d", "env.ini")).To(test.HaveContent("max_execution_time = 60\n"))
+		})
+
+		it("warns that PHP_INI_post_max_size & PHP_INI_upload_max_filesize do not change the web server limits", func() {
+			layer := factory.Build.Layers.Layer("layer-1")
+			Expect(p.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
+			tmpDir, err := ioutil.TempDir("", "php-ini-env")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(tmpDir)
+
+			cmd := exec.Command("bash", filepath.Join(layer.Root, "profile.d", "php-ini-env.sh"))
+			cmd.Env = []string{"PATH=" + os.Getenv("PATH"), "TMPDIR=" + tmpDir, "PHP_INI_post_max_size=64M", "PHP_INI_upload_max_filesize=64M"}
+			output, err := cmd.CombinedOutput()
+			Expect(err).ToNot(HaveOccurred(), string(output))
+			Expect(string(output)).To(ContainSubstring("PHP_INI_post_max_size does not change the largest request body httpd & nginx accept"))
+			Expect(string(output)).To(ContainSubstring("PHP_INI_upload_max_filesize does not change the largest request body httpd & nginx accept"))
+		})
 	})
 }
@@ -6,22 +6,28 @@ import (
 	"strings"
 
 	"github.com/buildpack/libbuildpack/application"
+	"github.com/cloudfoundry/libcfbuildpack/helper"
 	"github.com/cloudfoundry/libcfbuildpack/layers"
+	"github.com/cloudfoundry/libcfbuildpack/logger"
 
 	"github.com/paketo-buildpacks/php-web/config"
 )
 
 type PhpWebServerFeature struct {
-	bpYAML config.BuildpackYAML
-	app    application.Application
-	isWebApp bool
+	bpYAML    config.BuildpackYAML
+	app       application.Application
+	isWebApp  bool
+	processes config.Processes
+	logger    logger.Logger
 }
 
 func NewPhpWebServerFeature(featureConfig FeatureConfig) PhpWebServerFeature {
 	return PhpWebServerFeature{
-		bpYAML: featureConfig.BpYAML,
-		app:    featureConfig.App,
-		isWebApp: featureConfig.IsWebApp,
+		bpYAML:    featureConfig.BpYAML,
+		app:       featureConfig.App,
+		isWebApp:  featureConfig.IsWebApp,
+		processes: featureConfig.Processes,
+		logger:    featureConfig.Logger,
 	}
 }
 
@@ -33,14 +39,50 @@ func (p PhpWebServerFeature) Name() string {
 	return "PHP Web Server"
 }
 
-func (p PhpWebServerFeature) EnableFeature(commonLayers layers.Layers, _ layers.Layer) error {
+func (p PhpWebServerFeature) EnableFeature(commonLayers layers.Layers, currentLayer layers.Layer) error {
 	webdir := filepath.Join(p.app.Root, p.bpYAML.Config.WebDirectory)
-	command := fmt.Sprintf("php -S 0.0.0.0:$PORT -t %s", webdir)
-
-	return commonLayers.WriteApplicationMetadata(layers.Metadata{
-		Processes: []layers.Process{
-			{Type: "web", Command: command, Direct: false},
-			{Type: "task", Command: command, Direct: false},
-		},
-	})
+	serverConfig := p.bpYAML.Config.PhpServer
+
+	// procmgr doesn't run the built-in web server, so nothing reloads it
+	if p.bpYAML.Config.LiveReload {
+		p.logger.BodyWarning("WARNING: php.live_reload only makes OPcache check for changed scripts with php-server, changes to .php.ini.d need a restart")
+	}
+
+	cfg := config.PhpRouterConfig{
+		SecurityHeaders: p.bpYAML.Config.SecurityHeaders.Resolve(),
+		FrontController: serverConfig.FrontController,
+	}
+
+	if serverConfig.Router != "" {
+		cfg.Router = filepath.Join(p.app.Root, serverConfig.Router)
+		if exists, err := helper.FileExists(cfg.Router); err != nil {
+			return err
+		} else if !exists {
+			return fmt.Errorf("router script %s does not exist", serverConfig.Router)
+		}
+	}
+
+	// the router script adds response headers, denies hidden files & routes through the front controller or user's router
+	routerPath := filepath.Join(currentLayer.Root, "etc", "router.php")
+	if err := config.ProcessTemplateToFile(config.PhpRouterTemplate, routerPath, cfg); err != nil {
+		return err
+	}
+
+	// a default, so it can still be changed when launching
+	if serverConfig.Workers > 0 {
+		if err := currentLayer.DefaultLaunchEnv("PHP_CLI_SERVER_WORKERS", "%d", serverConfig.Workers); err != nil {
+			return err
+		}
+	}
+
+	// PHP's CLI ignores max_execution_time in php.ini, so it's set on the command line to the same value
+	maxExecutionTime := config.PhpIniConfig{RequestTimeout: p.bpYAML.Config.RequestTimeout}.MaxExecutionTime()
+	command := fmt.Sprintf("php -S ${PHP_SERVER_HOST:-0.0.0.0}:$PORT -d max_execution_time=%d -t %s %s", maxExecutionTime, webdir, routerPath)
+
+	// a declared `web` process takes the place of the built-in web server, the router script is still available to it
+	if declared, ok := p.processes["web"]; ok {
+		command = declared
+	}
+
+	return writeLaunchProcesses(commonLayers, p.processes, command)
 }
@@ -1,11 +1,15 @@
 package features_test
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"path/filepath"
 	"testing"
 
+	bp "github.com/buildpack/libbuildpack/logger"
 	"github.com/cloudfoundry/libcfbuildpack/layers"
+	"github.com/cloudfoundry/libcfbuildpack/logger"
 
 	"github.com/paketo-buildpacks/php-web/config"
 	"github.com/paketo-buildpacks/php-web/features"
@@ -87,19 +91,126 @@ func testPhpWebServer(t *testing.T, when spec.G, it spec.S) {
 		})
 
 		it("sets start command on the layers object", func() {
+			layer := factory.Build.Layers.Layer("layer-1")
+			Expect(p.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
 			expectedCommand := fmt.Sprintf(
-				"php -S 0.0.0.0:$PORT -t %s",
+				"php -S ${PHP_SERVER_HOST:-0.0.0.0}:$PORT -d max_execution_time=30 -t %s %s",
 				filepath.Join(factory.Build.Application.Root, "some-dir"),
+				filepath.Join(layer.Root, "etc", "router.php"),
 			)
-			layer := factory.Build.Layers.Layer("layer-1")
-			Expect(p.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
 			Expect(factory.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
 				Processes: []layers.Process{
-					{Type: "task", Command: expectedCommand, Direct: false},
+					{Type: "task", Command: features.DefaultTaskCommand, Direct: false},
 					{Type: "web", Command: expectedCommand, Direct: false},
 				},
 			}))
 		})
 
+		it("routes through the front controller & denies hidden files", func() {
+			p = features.NewPhpWebServerFeature(
+				features.FeatureConfig{
+					App: factory.Build.Application,
+					BpYAML: config.BuildpackYAML{Config: config.Config{
+						WebServer:    config.PhpWebServer,
+						WebDirectory: "some-dir",
+						PhpServer:    config.PhpServer{FrontController: "app.php"},
+					}},
+					IsWebApp: true,
+				},
+			)
+
+			layer := factory.Build.Layers.Layer("layer-1")
+			Expect(p.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
+			contents, err := ioutil.ReadFile(filepath.Join(layer.Root, "etc", "router.php"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(ContainSubstring("$segment[0] === '.'"))
+			Expect(string(contents)).To(ContainSubstring("$frontController = '/app.php';"))
+			Expect(string(contents)).NotTo(ContainSubstring("header("))
+			Expect(filepath.Join(layer.Root, "env.launch", "PHP_CLI_SERVER_WORKERS.default")).NotTo(BeAnExistingFile())
+		})
+
+		it("adds security headers in the router script", func() {
+			p = features.NewPhpWebServerFeature(
+				features.FeatureConfig{
+					App: factory.Build.Application,
+					BpYAML: config.BuildpackYAML{Config: config.Config{
+						WebServer:       config.PhpWebServer,
+						WebDirectory:    "some-dir",
+						SecurityHeaders: config.SecurityHeaders{Profile: config.SecurityHeadersRecommended},
+					}},
+					IsWebApp: true,
+				},
+			)
+
+			layer := factory.Build.Layers.Layer("layer-1")
+			Expect(p.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
+			contents, err := ioutil.ReadFile(filepath.Join(layer.Root, "etc", "router.php"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(ContainSubstring("header('X-Content-Type-Options: nosniff');"))
+		})
+
+		it("hands requests to the application's router script & sets the workers", func() {
+			routerPath := filepath.Join(factory.Build.Application.Root, "bin", "router.php")
+			test.WriteFile(t, routerPath, "<?php return false;")
+
+			p = features.NewPhpWebServerFeature(
+				features.FeatureConfig{
+					App: factory.Build.Application,
+					BpYAML: config.BuildpackYAML{Config: config.Config{
+						WebServer:    config.PhpWebServer,
+						WebDirectory: "some-dir",
+						PhpServer:    config.PhpServer{FrontController: "index.php", Router: "bin/router.php", Workers: 4},
+					}},
+					IsWebApp: true,
+				},
+			)
+
+			layer := factory.Build.Layers.Layer("layer-1")
+			Expect(p.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
+			contents, err := ioutil.ReadFile(filepath.Join(layer.Root, "etc", "router.php"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(ContainSubstring(fmt.Sprintf("return require '%s';", routerPath)))
+			Expect(string(contents)).NotTo(ContainSubstring("$frontController"))
+			Expect(layer).To(test.HaveDefaultLaunchEnvironment("PHP_CLI_SERVER_WORKERS", "4"))
+		})
+
+		it("fails when the application's router script is missing", func() {
+			p = features.NewPhpWebServerFeature(
+				features.FeatureConfig{
+					App: factory.Build.Application,
+					BpYAML: config.BuildpackYAML{Config: config.Config{
+						WebServer:    config.PhpWebServer,
+						WebDirectory: "some-dir",
+						PhpServer:    config.PhpServer{FrontController: "index.php", Router: "missing.php"},
+					}},
+					IsWebApp: true,
+				},
+			)
+
+			Expect(p.EnableFeature(factory.Build.Layers, factory.Build.Layers.Layer("layer-1"))).To(MatchError("router script missing.php does not exist"))
+		})
+
+		it("warns that live reload only applies to scripts", func() {
+			buf := bytes.NewBuffer(nil)
+			p = features.NewPhpWebServerFeature(
+				features.FeatureConfig{
+					App: factory.Build.Application,
+					BpYAML: config.BuildpackYAML{Config: config.Config{
+						WebServer:    config.PhpWebServer,
+						WebDirectory: "some-dir",
+						LiveReload:   true,
+					}},
+					IsWebApp: true,
+					Logger:   logger.Logger{Logger: bp.NewLogger(buf, buf)},
+				},
+			)
+
+			Expect(p.EnableFeature(factory.Build.Layers, factory.Build.Layers.Layer("layer-1"))).To(Succeed())
+			Expect(buf.String()).To(ContainSubstring("php.live_reload only makes OPcache check for changed scripts with php-server"))
+		})
 	})
 }
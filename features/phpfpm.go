@@ -54,9 +54,10 @@ func (p PhpFpmFeature) writeConfig(currentLayer layers.Layer) error {
 	}
 
 	cfg := config.PhpFpmConfig{
-		PhpHome: currentLayer.Root,
-		PhpAPI:  os.Getenv("PHP_API"),
-		Include: userIncludePath,
+		PhpHome:        currentLayer.Root,
+		PhpAPI:         os.Getenv("PHP_API"),
+		Include:        userIncludePath,
+		RequestTimeout: p.bpYAML.Config.RequestTimeout,
 	}
 
 	if p.bpYAML.Config.WebServer == config.ApacheHttpd {
@@ -77,12 +78,20 @@ func (p PhpFpmFeature) updateProcs(layer layers.Layer) error {
 	}
 
 	procsYaml := filepath.Join(layer.Root, "procs.yml")
+	proc := procmgr.Proc{
+		Command: "php-fpm",
+		Args:    []string{"-p", layer.Root, "-y", filepath.Join(layer.Root, "etc", "php-fpm.conf"), "-c", filepath.Join(layer.Root, "etc")},
+	}
+
+	// SIGUSR2 gracefully reloads the workers, the configuration & php.ini
+	if p.bpYAML.Config.LiveReload {
+		proc.Watch = []string{filepath.Join(p.app.Root, ".php.ini.d"), filepath.Join(p.app.Root, ".php.fpm.d")}
+		proc.ReloadSignal = "USR2"
+	}
+
 	procs := procmgr.Procs{
 		Processes: map[string]procmgr.Proc{
-			"php-fpm": {
-				Command: "php-fpm",
-				Args:    []string{"-p", layer.Root, "-y", filepath.Join(layer.Root, "etc", "php-fpm.conf"), "-c", filepath.Join(layer.Root, "etc")},
-			},
+			"php-fpm": proc,
 		},
 	}
 
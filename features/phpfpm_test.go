@@ -138,6 +138,31 @@ func testPhpFpm(t *testing.T, when spec.G, it spec.S) {
 					}))
 				})
 			}
+
+			it("reloads php-fpm when its configuration or php.ini changes, with live reload", func() {
+				p = features.NewPhpFpmFeature(
+					features.FeatureConfig{
+						BpYAML: config.BuildpackYAML{Config: config.Config{
+							WebServer:    webServer,
+							WebDirectory: "some-dir",
+							LiveReload:   true,
+						}},
+						App:      factory.Build.Application,
+						IsWebApp: true,
+					},
+				)
+
+				layer := factory.Build.Layers.Layer("layer-1")
+				Expect(p.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
+				procs, err := procmgr.ReadProcs(filepath.Join(layer.Root, "procs.yml"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(procs.Processes["php-fpm"].Watch).To(Equal([]string{
+					filepath.Join(factory.Build.Application.Root, ".php.ini.d"),
+					filepath.Join(factory.Build.Application.Root, ".php.fpm.d"),
+				}))
+				Expect(procs.Processes["php-fpm"].ReloadSignal).To(Equal("USR2"))
+			})
 		}
 	})
 }
@@ -0,0 +1,153 @@
+package features
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/buildpack/libbuildpack/application"
+	"github.com/cloudfoundry/libcfbuildpack/layers"
+	"github.com/cloudfoundry/libcfbuildpack/logger"
+	"github.com/paketo-buildpacks/php-web/config"
+)
+
+// PrecompressFeature writes `.gz` sidecars of static files at build time, which the web server serves instead of compressing on the fly.
+// There is no brotli encoder available at build time, so `.br` sidecars are only served by httpd, when they're included with the app. Nginx only serves `.gz` sidecars.
+type PrecompressFeature struct {
+	bpYAML   config.BuildpackYAML
+	app      application.Application
+	isWebApp bool
+	logger   logger.Logger
+}
+
+func NewPrecompressFeature(featureConfig FeatureConfig) PrecompressFeature {
+	return PrecompressFeature{
+		bpYAML:   featureConfig.BpYAML,
+		app:      featureConfig.App,
+		isWebApp: featureConfig.IsWebApp,
+		logger:   featureConfig.Logger,
+	}
+}
+
+func (p PrecompressFeature) IsNeeded() bool {
+	serverName := strings.ToLower(p.bpYAML.Config.WebServer)
+	return p.bpYAML.Config.StaticAssets.Precompressed && p.isWebApp && (serverName == config.Nginx || serverName == config.ApacheHttpd)
+}
+
+func (p PrecompressFeature) Name() string {
+	return "Precompress Static Assets"
+}
+
+func (p PrecompressFeature) EnableFeature(_ layers.Layers, _ layers.Layer) error {
+	extensions := map[string]bool{}
+	for _, extension := range p.bpYAML.Config.StaticAssets.CompressibleExtensions() {
+		extensions["."+extension] = true
+	}
+
+	webdir := filepath.Join(p.app.Root, p.bpYAML.Config.WebDirectory)
+	count := 0
+
+	err := filepath.Walk(webdir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// skip hidden files & directories, the web server will not serve them
+		if path != webdir && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !info.Mode().IsRegular() || !extensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		if info.Size() < int64(p.bpYAML.Config.StaticAssets.PrecompressMinSize) {
+			return nil
+		}
+
+		written, err := writeGzipSidecar(path, info)
+		if err != nil {
+			return err
+		}
+
+		if written {
+			count++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	p.logger.Body("Precompressed %d static files", count)
+	return nil
+}
+
+// writeGzipSidecar writes `<path>.gz`, unless one already exists or compressing doesn't make the file smaller
+func writeGzipSidecar(path string, info os.FileInfo) (bool, error) {
+	sidecar := path + ".gz"
+	if _, err := os.Stat(sidecar); err == nil {
+		return false, nil
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+
+	in, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(sidecar, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return false, err
+	}
+
+	size, err := gzipTo(out, in, info)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+
+	// a partial sidecar would be served in place of the file, so it's removed on any error
+	if err == nil && size < info.Size() {
+		err = os.Chtimes(sidecar, info.ModTime(), info.ModTime())
+		if err == nil {
+			return true, nil
+		}
+	}
+
+	if removeErr := os.Remove(sidecar); err == nil {
+		err = removeErr
+	}
+	return false, err
+}
+
+// gzipTo compresses in to out, returning the compressed size
+func gzipTo(out *os.File, in io.Reader, info os.FileInfo) (int64, error) {
+	writer, err := gzip.NewWriterLevel(out, gzip.BestCompression)
+	if err != nil {
+		return 0, err
+	}
+	writer.Name = info.Name()
+	writer.ModTime = info.ModTime()
+
+	if _, err := io.Copy(writer, in); err != nil {
+		return 0, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return 0, err
+	}
+
+	compressed, err := out.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return compressed.Size(), nil
+}
@@ -0,0 +1,109 @@
+package features_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudfoundry/libcfbuildpack/test"
+	"github.com/paketo-buildpacks/php-web/config"
+	"github.com/paketo-buildpacks/php-web/features"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestUnitPrecompress(t *testing.T) {
+	spec.Run(t, "Precompress", testPrecompress, spec.Report(report.Terminal{}))
+}
+
+func testPrecompress(t *testing.T, when spec.G, it spec.S) {
+	it.Before(func() {
+		RegisterTestingT(t)
+	})
+
+	when("static assets are precompressed", func() {
+		var (
+			factory *test.BuildFactory
+			p       features.PrecompressFeature
+			webdir  string
+		)
+
+		newFeature := func(webServer string, precompressed bool, isWebApp bool) features.PrecompressFeature {
+			return features.NewPrecompressFeature(
+				features.FeatureConfig{
+					BpYAML: config.BuildpackYAML{Config: config.Config{
+						WebServer:    webServer,
+						WebDirectory: "htdocs",
+						StaticAssets: config.StaticAssets{
+							GzipTypes:          config.DefaultGzipTypes,
+							Precompressed:      precompressed,
+							PrecompressMinSize: 1024,
+						},
+					}},
+					App:      factory.Build.Application,
+					IsWebApp: isWebApp,
+					Logger:   factory.Build.Logger,
+				},
+			)
+		}
+
+		it.Before(func() {
+			factory = test.NewBuildFactory(t)
+			webdir = filepath.Join(factory.Build.Application.Root, "htdocs")
+			p = newFeature(config.Nginx, true, true)
+		})
+
+		when("checking if IsNeeded", func() {
+			it("is true for nginx & httpd web apps", func() {
+				Expect(p.IsNeeded()).To(BeTrue())
+				Expect(newFeature(config.ApacheHttpd, true, true).IsNeeded()).To(BeTrue())
+			})
+
+			it("is false when not enabled", func() {
+				Expect(newFeature(config.Nginx, false, true).IsNeeded()).To(BeFalse())
+			})
+
+			it("is false for the built-in web server", func() {
+				Expect(newFeature(config.PhpWebServer, true, true).IsNeeded()).To(BeFalse())
+			})
+
+			it("is false when it is not a web app", func() {
+				Expect(newFeature(config.Nginx, true, false).IsNeeded()).To(BeFalse())
+			})
+		})
+
+		it("writes gzip sidecars for large compressible files", func() {
+			large := strings.Repeat("body { color: red; }\n", 100)
+			test.WriteFile(t, filepath.Join(webdir, "css", "app.css"), large)
+			test.WriteFile(t, filepath.Join(webdir, "small.js"), "var a = 1;")
+			test.WriteFile(t, filepath.Join(webdir, "image.png"), large)
+			test.WriteFile(t, filepath.Join(webdir, ".hidden", "secret.css"), large)
+
+			Expect(p.EnableFeature(factory.Build.Layers, factory.Build.Layers.Layer("layer-1"))).To(Succeed())
+
+			Expect(filepath.Join(webdir, "css", "app.css.gz")).To(BeARegularFile())
+			Expect(filepath.Join(webdir, "small.js.gz")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(webdir, "image.png.gz")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(webdir, ".hidden", "secret.css.gz")).NotTo(BeAnExistingFile())
+
+			original, err := os.Stat(filepath.Join(webdir, "css", "app.css"))
+			Expect(err).NotTo(HaveOccurred())
+			sidecar, err := os.Stat(filepath.Join(webdir, "css", "app.css.gz"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sidecar.Size()).To(BeNumerically("<", original.Size()))
+			Expect(sidecar.ModTime()).To(Equal(original.ModTime()))
+		})
+
+		it("keeps sidecars which are included with the app", func() {
+			test.WriteFile(t, filepath.Join(webdir, "app.css"), strings.Repeat("body { color: red; }\n", 100))
+			test.WriteFile(t, filepath.Join(webdir, "app.css.gz"), "original")
+
+			Expect(p.EnableFeature(factory.Build.Layers, factory.Build.Layers.Layer("layer-1"))).To(Succeed())
+
+			Expect(filepath.Join(webdir, "app.css.gz")).To(test.HaveContent("original"))
+		})
+	})
+}
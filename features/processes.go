@@ -0,0 +1,37 @@
+package features
+
+import (
+	"sort"
+
+	"github.com/cloudfoundry/libcfbuildpack/layers"
+	"github.com/paketo-buildpacks/php-web/config"
+)
+
+// DefaultTaskCommand is the `task` process, an interactive PHP shell, unless the user declares their own
+const DefaultTaskCommand = "php -a"
+
+// writeLaunchProcesses writes the `web` process along with the `task` process & any other processes declared by the user
+func writeLaunchProcesses(commonLayers layers.Layers, declared config.Processes, webCommand string) error {
+	processes := []layers.Process{
+		{Type: "web", Command: webCommand, Direct: false},
+	}
+
+	if _, ok := declared["task"]; !ok {
+		processes = append(processes, layers.Process{Type: "task", Command: DefaultTaskCommand, Direct: false})
+	}
+
+	// sorted, as map order would change the metadata from one build to the next
+	var processTypes []string
+	for processType := range declared {
+		if processType != "web" {
+			processTypes = append(processTypes, processType)
+		}
+	}
+	sort.Strings(processTypes)
+
+	for _, processType := range processTypes {
+		processes = append(processes, layers.Process{Type: processType, Command: declared[processType], Direct: false})
+	}
+
+	return commonLayers.WriteApplicationMetadata(layers.Metadata{Processes: processes})
+}
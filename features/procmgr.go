@@ -7,24 +7,27 @@ import (
 	"github.com/cloudfoundry/libcfbuildpack/helper"
 	"github.com/cloudfoundry/libcfbuildpack/layers"
 	"github.com/paketo-buildpacks/php-web/config"
+	"github.com/paketo-buildpacks/php-web/procmgr"
 )
 
 type ProcMgrFeature struct {
 	bpYAML      config.BuildpackYAML
 	procMgrPath string
-	isWebApp bool
+	isWebApp    bool
+	processes   config.Processes
 }
 
 func NewProcMgrFeature(featureConfig FeatureConfig, procMgrPath string) ProcMgrFeature {
 	return ProcMgrFeature{
 		bpYAML:      featureConfig.BpYAML,
-		isWebApp:   featureConfig.IsWebApp,
+		isWebApp:    featureConfig.IsWebApp,
 		procMgrPath: procMgrPath,
+		processes:   featureConfig.Processes,
 	}
 }
 
 func (p ProcMgrFeature) IsNeeded() bool {
-	return p.bpYAML.Config.WebServer == config.Nginx || p.bpYAML.Config.WebServer == config.ApacheHttpd
+	return (p.bpYAML.Config.WebServer == config.Nginx || p.bpYAML.Config.WebServer == config.ApacheHttpd) && p.isWebApp
 }
 
 func (p ProcMgrFeature) Name() string {
@@ -39,10 +42,63 @@ func (p ProcMgrFeature) EnableFeature(currentLayers layers.Layers, currentLayer
 
 	procsYaml := filepath.Join(currentLayer.Root, "procs.yml")
 
-	return currentLayers.WriteApplicationMetadata(layers.Metadata{
-		Processes: []layers.Process{
-			{Type: "web", Command: fmt.Sprintf("procmgr %s", procsYaml), Direct: false},
-		},
-	})
+	if command, ok := p.processes["web"]; ok {
+		if err := p.replaceWebServer(procsYaml, command); err != nil {
+			return err
+		}
+	}
+
+	if err := p.addWorkers(currentLayer, procsYaml); err != nil {
+		return err
+	}
+
+	return writeLaunchProcesses(currentLayers, p.processes, fmt.Sprintf("procmgr %s", procsYaml))
+}
+
+// replaceWebServer runs a declared `web` process in place of httpd or nginx, alongside php-fpm
+func (p ProcMgrFeature) replaceWebServer(procsYaml string, command string) error {
+	procs, err := procmgr.ReadProcs(procsYaml)
+	if err != nil {
+		return err
+	}
+
+	delete(procs.Processes, p.bpYAML.Config.WebServer)
+	procs.Processes["web"] = procmgr.Proc{
+		Command: "bash",
+		Args:    []string{"-c", command},
+	}
+
+	return procmgr.WriteProcs(procsYaml, procs)
+}
+
+// addWorkers supervises the configured workers & the scheduler, when there's a schedule, next to php-fpm
+func (p ProcMgrFeature) addWorkers(currentLayer layers.Layer, procsYaml string) error {
+	procs := procmgr.Procs{Processes: map[string]procmgr.Proc{}}
+
+	for name, worker := range p.bpYAML.Config.Workers {
+		procs.Processes[name] = procmgr.Proc{
+			Command:  "bash",
+			Args:     []string{"-c", worker.Command},
+			Replicas: worker.Replicas,
+			Restart:  true,
+		}
+	}
+
+	if len(p.bpYAML.Config.Schedule) > 0 {
+		schedulePath := filepath.Join(currentLayer.Root, "etc", "schedule")
+		if err := procmgr.WriteSchedule(schedulePath, p.bpYAML.Config.Schedule); err != nil {
+			return err
+		}
+
+		procs.Processes["scheduler"] = procmgr.Proc{
+			Command: filepath.Join(currentLayer.Root, "bin", "procmgr"),
+			Args:    []string{"--schedule", schedulePath},
+		}
+	}
+
+	if len(procs.Processes) == 0 {
+		return nil
+	}
 
+	return procmgr.AppendOrUpdateProcs(procsYaml, procs)
 }
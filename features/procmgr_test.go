@@ -12,6 +12,7 @@ import (
 
 	"github.com/paketo-buildpacks/php-web/config"
 	"github.com/paketo-buildpacks/php-web/features"
+	"github.com/paketo-buildpacks/php-web/procmgr"
 
 	"github.com/cloudfoundry/libcfbuildpack/test"
 	"github.com/sclevine/spec"
@@ -79,7 +80,10 @@ func testProcMgr(t *testing.T, when spec.G, it spec.S) {
 						it("is false", func() {
 							p = features.NewProcMgrFeature(
 								features.FeatureConfig{
-									BpYAML:   config.BuildpackYAML{Config: config.Config{}},
+									BpYAML: config.BuildpackYAML{Config: config.Config{
+										WebServer: webServer,
+										Workers:   config.Workers{"queue": config.Worker{Command: "php artisan queue:work"}},
+									}},
 									App:      factory.Build.Application,
 									IsWebApp: false,
 								},
@@ -102,8 +106,9 @@ func testProcMgr(t *testing.T, when spec.G, it spec.S) {
 						BpYAML: config.BuildpackYAML{Config: config.Config{
 							WebServer: config.ApacheHttpd,
 						}},
-						App:      factory.Build.Application,
-						IsWebApp: true,
+						App:       factory.Build.Application,
+						IsWebApp:  true,
+						Processes: config.Processes{"worker": "php bin/worker.php"},
 					},
 					procMgrPath,
 				)
@@ -117,10 +122,86 @@ func testProcMgr(t *testing.T, when spec.G, it spec.S) {
 
 				Expect(factory.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
 					Processes: []layers.Process{
+						{Type: "task", Command: features.DefaultTaskCommand, Direct: false},
+						{Type: "web", Command: fmt.Sprintf("procmgr %s", procsYMLPath), Direct: false},
+						{Type: "worker", Command: "php bin/worker.php", Direct: false},
+					},
+				}))
+			})
+
+			it("runs a declared web process in place of the web server, alongside php-fpm", func() {
+				currentLayer := factory.Build.Layers.Layer("layer-1")
+				procMgrPath := filepath.Join(factory.Build.Buildpack.Root, "procMgr")
+				procsYMLPath := filepath.Join(currentLayer.Root, "procs.yml")
+
+				p = features.NewProcMgrFeature(
+					features.FeatureConfig{
+						BpYAML: config.BuildpackYAML{Config: config.Config{
+							WebServer: config.Nginx,
+						}},
+						App:       factory.Build.Application,
+						IsWebApp:  true,
+						Processes: config.Processes{"web": "nginx -c custom.conf"},
+					},
+					procMgrPath,
+				)
+
+				Expect(helper.WriteFile(procMgrPath, os.ModePerm, "some content")).To(Succeed())
+				Expect(procmgr.WriteProcs(procsYMLPath, procmgr.Procs{Processes: map[string]procmgr.Proc{
+					"nginx":   {Command: "nginx"},
+					"php-fpm": {Command: "php-fpm", Args: []string{}},
+				}})).To(Succeed())
+
+				Expect(p.EnableFeature(factory.Build.Layers, currentLayer)).To(Succeed())
+
+				procs, err := procmgr.ReadProcs(procsYMLPath)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(procs.Processes).To(Equal(map[string]procmgr.Proc{
+					"php-fpm": {Command: "php-fpm", Args: []string{}},
+					"web":     {Command: "bash", Args: []string{"-c", "nginx -c custom.conf"}},
+				}))
+
+				Expect(factory.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+					Processes: []layers.Process{
+						{Type: "task", Command: features.DefaultTaskCommand, Direct: false},
 						{Type: "web", Command: fmt.Sprintf("procmgr %s", procsYMLPath), Direct: false},
 					},
 				}))
 			})
+
+			it("supervises workers and the scheduler", func() {
+				currentLayer := factory.Build.Layers.Layer("layer-1")
+				procMgrPath := filepath.Join(factory.Build.Buildpack.Root, "procMgr")
+
+				p = features.NewProcMgrFeature(
+					features.FeatureConfig{
+						BpYAML: config.BuildpackYAML{Config: config.Config{
+							WebServer: config.Nginx,
+							Workers:   config.Workers{"queue": {Command: "php artisan queue:work", Replicas: 2}},
+							Schedule:  []string{"* * * * * php artisan schedule:run"},
+						}},
+						App:      factory.Build.Application,
+						IsWebApp: true,
+					},
+					procMgrPath,
+				)
+
+				Expect(helper.WriteFile(procMgrPath, os.ModePerm, "some content")).To(Succeed())
+				Expect(p.EnableFeature(factory.Build.Layers, currentLayer)).To(Succeed())
+
+				schedulePath := filepath.Join(currentLayer.Root, "etc", "schedule")
+				Expect(schedulePath).To(test.HaveContent("* * * * * php artisan schedule:run\n"))
+
+				procs, err := procmgr.ReadProcs(filepath.Join(currentLayer.Root, "procs.yml"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(procs.Processes).To(Equal(map[string]procmgr.Proc{
+					"queue": {Command: "bash", Args: []string{"-c", "php artisan queue:work"}, Replicas: 2, Restart: true},
+					"scheduler": {
+						Command: filepath.Join(currentLayer.Root, "bin", "procmgr"),
+						Args:    []string{"--schedule", schedulePath},
+					},
+				}))
+			})
 		})
 
 	})
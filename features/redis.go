@@ -3,18 +3,11 @@ package features
 import (
 	"bytes"
 	"fmt"
-	"io/ioutil"
 	"net/url"
-	"os"
 	"path/filepath"
 
 	"github.com/cloudfoundry/libcfbuildpack/helper"
-
-	"github.com/buildpack/libbuildpack/logger"
-	"github.com/buildpack/libbuildpack/platform"
 	"github.com/cloudfoundry/libcfbuildpack/layers"
-
-	lbservices "github.com/buildpack/libbuildpack/services"
 	"github.com/cloudfoundry/libcfbuildpack/services"
 )
 
@@ -93,24 +86,14 @@ func FromExistingRedisSessionSupport(featureConfig FeatureConfig, srvs services.
 }
 
 func NewRedisSessionSupport(platformRoot, appRoot string) (RedisSessionSupport, error) {
-	logger, err := logger.DefaultLogger(platformRoot)
-	if err != nil {
-		return RedisSessionSupport{}, err
-	}
-
-	platform, err := platform.DefaultPlatform(platformRoot, logger)
-	if err != nil {
-		return RedisSessionSupport{}, err
-	}
-
-	defaultServices, err := lbservices.DefaultServices(platform, logger)
+	srvs, err := loadServices(platformRoot)
 	if err != nil {
 		return RedisSessionSupport{}, err
 	}
 
 	return RedisSessionSupport{
 		appRoot:    appRoot,
-		services:   services.Services{Services: defaultServices},
+		services:   srvs,
 		serviceKey: "",
 	}, nil
 }
@@ -128,13 +111,15 @@ func (s RedisSessionSupport) ConfigureService() error {
 	buf.WriteString("session.save_handler=redis\n")
 	buf.WriteString(fmt.Sprintf("session.save_path=%q\n", savePath))
 
-	// don't use helper.WriteFile because it will mess up the URLencoded values
+	// contains credentials, so it must only be readable by the launch user
 	filename := filepath.Join(s.appRoot, ".php.ini.d", "redis-sessions.ini")
-	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
-		return err
-	}
+	return writeCredentialsFile(filename, buf.Bytes())
+}
 
-	return ioutil.WriteFile(filename, buf.Bytes(), 0644)
+// Redact masks any credentials from the bound service which appear in message
+func (s RedisSessionSupport) Redact(message string) string {
+	creds, _ := s.FindService()
+	return redactCredentials(message, creds)
 }
 
 func (s RedisSessionSupport) FindService() (services.Credentials, bool) {
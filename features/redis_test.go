@@ -298,6 +298,22 @@ session_helper \
 					Expect(string(contents)).To(ContainSubstring("session.save_handler=redis"))
 					Expect(string(contents)).To(ContainSubstring("session.save_path=\"tcp://192.168.0.1:65309?auth=fake%21%40%23%24%25%22%5E%26%2A%28%29-%3D%7B%5D%7D%5B%3F%3E%3C%2C.%2F%3B%27%3A\""))
 				})
+
+				it("is only readable by the launch user", func() {
+					iniPath := filepath.Join(factory.Build.Application.Root, ".php.ini.d", "redis-sessions.ini")
+					test.WriteFile(t, iniPath, "stale")
+
+					Expect(sessionSupport.ConfigureService()).To(Succeed())
+
+					info, err := os.Stat(iniPath)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+				})
+
+				it("redacts the credentials from messages", func() {
+					message := sessionSupport.Redact("tcp://192.168.0.1:65309?auth=fake%21%40%23%24%25%22%5E%26%2A%28%29-%3D%7B%5D%7D%5B%3F%3E%3C%2C.%2F%3B%27%3A")
+					Expect(message).To(Equal(fmt.Sprintf("tcp://192.168.0.1:65309?auth=%s", config.Redacted)))
+				})
 			})
 		})
 	})
@@ -3,6 +3,7 @@ package features
 import (
 	"fmt"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/buildpack/libbuildpack/application"
@@ -12,19 +13,23 @@ import (
 	"github.com/paketo-buildpacks/php-web/config"
 )
 
+var safeShellPattern = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
 type ScriptsFeature struct {
-	bpYAML   config.BuildpackYAML
-	app      application.Application
-	isWebApp bool
-	logger   logger.Logger
+	bpYAML    config.BuildpackYAML
+	app       application.Application
+	isWebApp  bool
+	logger    logger.Logger
+	processes config.Processes
 }
 
 func NewScriptsFeature(featureConfig FeatureConfig) ScriptsFeature {
 	return ScriptsFeature{
-		bpYAML:   featureConfig.BpYAML,
-		app:      featureConfig.App,
-		isWebApp: featureConfig.IsWebApp,
-		logger:   featureConfig.Logger,
+		bpYAML:    featureConfig.BpYAML,
+		app:       featureConfig.App,
+		isWebApp:  featureConfig.IsWebApp,
+		logger:    featureConfig.Logger,
+		processes: featureConfig.Processes,
 	}
 }
 
@@ -37,33 +42,67 @@ func (p ScriptsFeature) Name() string {
 }
 
 func (p ScriptsFeature) EnableFeature(commonLayers layers.Layers, currentLayer layers.Layer) error {
-	if p.bpYAML.Config.Script == "" {
-		for _, possible := range config.DefaultCliScripts {
-			exists, err := helper.FileExists(filepath.Join(p.app.Root, possible))
-			if err != nil {
-				return err
-				// skip and continue
-			}
+	// a declared `web` process takes the place of the script
+	if command, ok := p.processes["web"]; ok {
+		return writeLaunchProcesses(commonLayers, p.processes, command)
+	}
 
-			if exists {
-				p.bpYAML.Config.Script = possible
-				break
-			}
+	script, err := p.findScript()
+	if err != nil {
+		return err
+	}
+
+	command := fmt.Sprintf("php %s", filepath.Join(p.app.Root, script))
+	for _, arg := range p.bpYAML.Config.ScriptArgs {
+		command = fmt.Sprintf("%s %s", command, shellQuote(arg))
+	}
+
+	return writeLaunchProcesses(commonLayers, p.processes, command)
+}
+
+// findScript picks the script to run, `php.script`, the first `bin` of composer.json or one of DefaultCliScripts
+func (p ScriptsFeature) findScript() (string, error) {
+	if p.bpYAML.Config.Script != "" {
+		exists, err := helper.FileExists(filepath.Join(p.app.Root, p.bpYAML.Config.Script))
+		if err != nil {
+			return "", err
+		}
+
+		if !exists {
+			return "", fmt.Errorf("php.script %s does not exist", p.bpYAML.Config.Script)
 		}
 
-		if p.bpYAML.Config.Script == "" {
-			p.bpYAML.Config.Script = "app.php"
-			p.logger.BodyWarning("Buildpack could not find a file to execute. Either set php.script in buildpack.yml or include one of these files [%s]", strings.Join(config.DefaultCliScripts, ", "))
+		return p.bpYAML.Config.Script, nil
+	}
+
+	composerJSON, err := config.LoadComposerJSON(p.app.Root)
+	if err != nil {
+		return "", err
+	}
+
+	candidates := append(append([]string{}, composerJSON.Bin...), config.DefaultCliScripts...)
+	for _, possible := range candidates {
+		exists, err := helper.FileExists(filepath.Join(p.app.Root, possible))
+		if err != nil {
+			return "", err
+		}
+
+		if exists {
+			if len(composerJSON.Bin) > 1 {
+				p.logger.Body("Using %s, set php.script in buildpack.yml to run another composer.json bin [%s]", possible, strings.Join(composerJSON.Bin, ", "))
+			}
+			return possible, nil
 		}
 	}
 
-	scriptPath := filepath.Join(p.app.Root, p.bpYAML.Config.Script)
-	command := fmt.Sprintf("php %s", scriptPath)
+	return "", fmt.Errorf("could not find a file to execute, either set php.script in buildpack.yml, declare a bin in composer.json or include one of these files [%s]", strings.Join(config.DefaultCliScripts, ", "))
+}
+
+// shellQuote quotes value for use as a single argument in a shell command, when necessary
+func shellQuote(value string) string {
+	if value != "" && safeShellPattern.MatchString(value) {
+		return value
+	}
 
-	return commonLayers.WriteApplicationMetadata(layers.Metadata{
-		Processes: []layers.Process{
-			{Type: "web", Command: command, Direct: false},
-			{Type: "task", Command: command, Direct: false},
-		},
-	})
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
 }
@@ -87,7 +87,7 @@ func testScripts(t *testing.T, when spec.G, it spec.S) {
 					command := fmt.Sprintf("php %s/%s", factory.Build.Application.Root, script)
 					Expect(factory.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
 						Processes: []layers.Process{
-							{Type: "task", Command: command, Direct: false},
+							{Type: "task", Command: features.DefaultTaskCommand, Direct: false},
 							{Type: "web", Command: command, Direct: false},
 						},
 					}))
@@ -98,6 +98,7 @@ func testScripts(t *testing.T, when spec.G, it spec.S) {
 
 			it("starts a script using custom script path/name", func() {
 				layer := factory.Build.Layers.Layer("layer-1")
+				test.WriteFile(t, filepath.Join(factory.Build.Application.Root, "relative/path/to/my/script.php"), "")
 
 				p = features.NewScriptsFeature(
 					features.FeatureConfig{
@@ -114,11 +115,124 @@ func testScripts(t *testing.T, when spec.G, it spec.S) {
 				command := fmt.Sprintf("php %s/%s", factory.Build.Application.Root, "relative/path/to/my/script.php")
 				Expect(factory.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
 					Processes: []layers.Process{
-						{Type: "task", Command: command, Direct: false},
+						{Type: "task", Command: features.DefaultTaskCommand, Direct: false},
 						{Type: "web", Command: command, Direct: false},
 					},
 				}))
 			})
+
+			it("passes the default arguments, quoted for the shell", func() {
+				layer := factory.Build.Layers.Layer("layer-1")
+				test.WriteFile(t, filepath.Join(factory.Build.Application.Root, "app.php"), "")
+
+				p = features.NewScriptsFeature(
+					features.FeatureConfig{
+						BpYAML: config.BuildpackYAML{Config: config.Config{
+							ScriptArgs: []string{"--env=prod", "it's here", ""},
+						}},
+						App:      factory.Build.Application,
+						IsWebApp: false,
+					},
+				)
+				Expect(p.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
+				command := fmt.Sprintf(`php %s/app.php --env=prod 'it'\''s here' ''`, factory.Build.Application.Root)
+				Expect(factory.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+					Processes: []layers.Process{
+						{Type: "task", Command: features.DefaultTaskCommand, Direct: false},
+						{Type: "web", Command: command, Direct: false},
+					},
+				}))
+			})
+
+			it("starts the first composer.json bin", func() {
+				layer := factory.Build.Layers.Layer("layer-1")
+				test.WriteFile(t, filepath.Join(factory.Build.Application.Root, "composer.json"), `{"bin": ["bin/missing", "bin/console", "bin/other"]}`)
+				test.WriteFile(t, filepath.Join(factory.Build.Application.Root, "bin", "console"), "")
+				test.WriteFile(t, filepath.Join(factory.Build.Application.Root, "bin", "other"), "")
+				test.WriteFile(t, filepath.Join(factory.Build.Application.Root, "app.php"), "")
+
+				p = features.NewScriptsFeature(
+					features.FeatureConfig{
+						App:      factory.Build.Application,
+						IsWebApp: false,
+						Logger:   factory.Build.Logger,
+					},
+				)
+				Expect(p.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
+				command := fmt.Sprintf("php %s/bin/console", factory.Build.Application.Root)
+				Expect(factory.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+					Processes: []layers.Process{
+						{Type: "task", Command: features.DefaultTaskCommand, Direct: false},
+						{Type: "web", Command: command, Direct: false},
+					},
+				}))
+			})
+
+			it("fails when there is no script to start", func() {
+				err := p.EnableFeature(factory.Build.Layers, factory.Build.Layers.Layer("layer-1"))
+				Expect(err).To(MatchError(ContainSubstring("could not find a file to execute")))
+			})
+
+			it("fails when the custom script does not exist", func() {
+				p = features.NewScriptsFeature(
+					features.FeatureConfig{
+						BpYAML:   config.BuildpackYAML{Config: config.Config{Script: "missing.php"}},
+						App:      factory.Build.Application,
+						IsWebApp: false,
+					},
+				)
+
+				err := p.EnableFeature(factory.Build.Layers, factory.Build.Layers.Layer("layer-1"))
+				Expect(err).To(MatchError("php.script missing.php does not exist"))
+			})
+
+			it("uses the task process declared by the user", func() {
+				layer := factory.Build.Layers.Layer("layer-1")
+				test.WriteFile(t, filepath.Join(factory.Build.Application.Root, "app.php"), "")
+
+				p = features.NewScriptsFeature(
+					features.FeatureConfig{
+						BpYAML: config.BuildpackYAML{Config: config.Config{
+							Script: "app.php",
+						}},
+						App:       factory.Build.Application,
+						IsWebApp:  false,
+						Processes: config.Processes{"task": "php bin/console", "migrate": "php bin/console migrate"},
+					},
+				)
+				Expect(p.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
+				command := fmt.Sprintf("php %s/%s", factory.Build.Application.Root, "app.php")
+				Expect(factory.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+					Processes: []layers.Process{
+						{Type: "migrate", Command: "php bin/console migrate", Direct: false},
+						{Type: "task", Command: "php bin/console", Direct: false},
+						{Type: "web", Command: command, Direct: false},
+					},
+				}))
+			})
+
+			it("uses the web process declared by the user in place of a script", func() {
+				layer := factory.Build.Layers.Layer("layer-1")
+
+				p = features.NewScriptsFeature(
+					features.FeatureConfig{
+						App:       factory.Build.Application,
+						IsWebApp:  false,
+						Processes: config.Processes{"web": "php bin/server.php"},
+					},
+				)
+				Expect(p.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
+				Expect(factory.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+					Processes: []layers.Process{
+						{Type: "task", Command: features.DefaultTaskCommand, Direct: false},
+						{Type: "web", Command: "php bin/server.php", Direct: false},
+					},
+				}))
+			})
 		})
 
 	})
@@ -0,0 +1,120 @@
+package features
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/buildpack/libbuildpack/logger"
+	"github.com/buildpack/libbuildpack/platform"
+	lbservices "github.com/buildpack/libbuildpack/services"
+
+	"github.com/cloudfoundry/libcfbuildpack/services"
+	"github.com/paketo-buildpacks/php-web/config"
+)
+
+// loadServices reads the services bound to the app from the platform, when session_helper runs at launch
+func loadServices(platformRoot string) (services.Services, error) {
+	// debug output is never enabled here, as it would print service credentials
+	logger := logger.NewLogger(nil, os.Stderr)
+
+	platform, err := platform.DefaultPlatform(platformRoot, logger)
+	if err != nil {
+		return services.Services{}, err
+	}
+
+	defaultServices, err := lbservices.DefaultServices(platform, logger)
+	if err != nil {
+		return services.Services{}, err
+	}
+
+	return services.Services{Services: defaultServices}, nil
+}
+
+// extensionSupport locates the service bound for an extension, by the binding name, label or tag serviceName, and is
+// shared by the New Relic, OpenTelemetry & Datadog support
+type extensionSupport struct {
+	appRoot      string
+	extensionDir string
+	services     services.Services
+	serviceName  string
+}
+
+func newExtensionSupport(appRoot, extensionDir string, srvs services.Services, serviceName string) extensionSupport {
+	return extensionSupport{
+		appRoot:      appRoot,
+		extensionDir: extensionDir,
+		services:     srvs,
+		serviceName:  serviceName,
+	}
+}
+
+// loadExtensionSupport is used by session_helper, which reads the bound services from the platform
+func loadExtensionSupport(platformRoot, appRoot, extensionDir, serviceName string) (extensionSupport, error) {
+	srvs, err := loadServices(platformRoot)
+	if err != nil {
+		return extensionSupport{}, err
+	}
+
+	return newExtensionSupport(appRoot, extensionDir, srvs, serviceName), nil
+}
+
+// FindService returns the credentials of the bound service
+func (e extensionSupport) FindService() (services.Credentials, bool) {
+	return e.services.FindServiceCredentials(e.serviceName)
+}
+
+// Redact masks any credentials from the bound service which appear in message
+func (e extensionSupport) Redact(message string) string {
+	creds, _ := e.FindService()
+	return redactCredentials(message, creds)
+}
+
+// writeCredentialsFile writes a credential bearing file which is only readable by the current (launch) user
+func writeCredentialsFile(filename string, contents []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+
+	// remove any previous copy so that the new file is created by, and owned by, the current user
+	if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	// don't use helper.WriteFile because it will mess up the URLencoded values
+	if err := ioutil.WriteFile(filename, contents, 0600); err != nil {
+		return err
+	}
+
+	// the umask does not apply to chmod, so this guarantees the final mode
+	return os.Chmod(filename, 0600)
+}
+
+// redactCredentials replaces any secret credential values found in message
+func redactCredentials(message string, creds services.Credentials) string {
+	for key, value := range creds {
+		secret, ok := value.(string)
+		if !ok || secret == "" || !config.IsSecret(key) {
+			continue
+		}
+
+		message = strings.ReplaceAll(message, fmt.Sprintf("%q", secret), config.Redacted)
+		message = strings.ReplaceAll(message, url.QueryEscape(secret), config.Redacted)
+		message = strings.ReplaceAll(message, secret, config.Redacted)
+	}
+
+	return message
+}
+
+// credentialString returns the first of keys which is set to a string in creds
+func credentialString(creds services.Credentials, keys ...string) string {
+	for _, key := range keys {
+		if value, ok := creds[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
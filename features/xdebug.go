@@ -0,0 +1,61 @@
+package features
+
+import (
+	"github.com/cloudfoundry/libcfbuildpack/layers"
+)
+
+// XdebugProfile is a profile.d script, which loads & configures Xdebug when `BP_PHP_XDEBUG`, or else `BP_DEBUG`, is
+// true at launch. Its ini file is written next to the one of `PHP_INI_*` variables, which are read after it, so
+// those can still override any setting. Nothing is changed when it's not enabled.
+const XdebugProfile = `php_xdebug_ini="${TMPDIR:-/tmp}/php-ini-env.d/debug.ini"
+rm -f "${php_xdebug_ini}"
+
+case "$(echo "${BP_PHP_XDEBUG:-${BP_DEBUG:-}}" | tr '[:upper:]' '[:lower:]')" in
+  true|yes|on|1)
+    if [[ -f "${PHP_EXTENSION_DIR:-}/xdebug.so" ]]; then
+      # quoted & escaped like config.QuoteIniValue
+      php_xdebug_client_host="$(printf '%s' "${BP_PHP_XDEBUG_CLIENT_HOST:-host.docker.internal}" | sed -e 's/[\\"$]/\\&/g')"
+      php_xdebug_idekey=""
+      if [[ -n "${BP_PHP_XDEBUG_IDEKEY:-}" ]]; then
+        php_xdebug_idekey="xdebug.idekey = \"$(printf '%s' "${BP_PHP_XDEBUG_IDEKEY}" | sed -e 's/[\\"$]/\\&/g')\""
+      fi
+
+      mkdir -p "$(dirname "${php_xdebug_ini}")"
+      cat > "${php_xdebug_ini}" <<EOF
+zend_extension = xdebug.so
+xdebug.mode = ${BP_PHP_XDEBUG_MODE:-debug}
+xdebug.client_host = "${php_xdebug_client_host}"
+xdebug.client_port = ${BP_PHP_XDEBUG_CLIENT_PORT:-9003}
+${php_xdebug_idekey}
+
+; breakpoints & stepping need unoptimized opcodes, and Xdebug can't be used with the JIT
+opcache.optimization_level = 0
+opcache.jit = off
+EOF
+    else
+      echo "Xdebug is not installed in ${PHP_EXTENSION_DIR:-the PHP extension directory}, it is not enabled" >&2
+    fi
+    ;;
+esac
+
+unset php_xdebug_ini php_xdebug_client_host php_xdebug_idekey
+`
+
+// XdebugFeature lets Xdebug be enabled when launching an image, without rebuilding it
+type XdebugFeature struct{}
+
+func NewXdebugFeature(featureConfig FeatureConfig) XdebugFeature {
+	return XdebugFeature{}
+}
+
+func (x XdebugFeature) IsNeeded() bool {
+	return true
+}
+
+func (x XdebugFeature) Name() string {
+	return "Xdebug"
+}
+
+func (x XdebugFeature) EnableFeature(commonLayers layers.Layers, currentLayer layers.Layer) error {
+	return currentLayer.WriteProfile("xdebug.sh", "%s", XdebugProfile)
+}
@@ -0,0 +1,109 @@
+package features_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudfoundry/libcfbuildpack/test"
+	"github.com/paketo-buildpacks/php-web/features"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestUnitXdebug(t *testing.T) {
+	spec.Run(t, "Xdebug", testXdebug, spec.Report(report.Terminal{}))
+}
+
+func testXdebug(t *testing.T, when spec.G, it spec.S) {
+	var (
+		factory *test.BuildFactory
+		x       features.XdebugFeature
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		factory = test.NewBuildFactory(t)
+		x = features.NewXdebugFeature(features.FeatureConfig{App: factory.Build.Application})
+	})
+
+	it("is always needed, as it's enabled at launch", func() {
+		Expect(x.IsNeeded()).To(BeTrue())
+	})
+
+	it("writes a profile script enabling Xdebug at launch", func() {
+		layer := factory.Build.Layers.Layer("layer-1")
+		Expect(x.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
+		Expect(layer).To(test.HaveProfile("xdebug.sh", "%s", features.XdebugProfile))
+	})
+
+	when("the profile script runs", func() {
+		var (
+			extensionDir string
+			tmpDir       string
+			iniPath      string
+		)
+
+		run := func(env ...string) string {
+			layer := factory.Build.Layers.Layer("layer-1")
+			Expect(x.EnableFeature(factory.Build.Layers, layer)).To(Succeed())
+
+			cmd := exec.Command("bash", filepath.Join(layer.Root, "profile.d", "xdebug.sh"))
+			cmd.Env = append([]string{
+				"PATH=" + os.Getenv("PATH"),
+				"TMPDIR=" + tmpDir,
+				"PHP_EXTENSION_DIR=" + extensionDir,
+			}, env...)
+			output, err := cmd.CombinedOutput()
+			Expect(err).ToNot(HaveOccurred(), string(output))
+
+			return string(output)
+		}
+
+		it.Before(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "xdebug")
+			Expect(err).ToNot(HaveOccurred())
+
+			extensionDir = filepath.Join(factory.Build.Platform.Root, "extensions")
+			iniPath = filepath.Join(tmpDir, "php-ini-env.d", "debug.ini")
+			test.WriteFile(t, filepath.Join(extensionDir, "xdebug.so"), "")
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(tmpDir)).To(Succeed())
+		})
+
+		it("writes nothing when BP_PHP_XDEBUG is not set", func() {
+			Expect(run()).To(BeEmpty())
+			Expect(iniPath).NotTo(BeAnExistingFile())
+		})
+
+		it("loads & configures Xdebug when BP_PHP_XDEBUG is true", func() {
+			Expect(run("BP_PHP_XDEBUG=true", "BP_PHP_XDEBUG_CLIENT_HOST=10.0.0.1", `BP_PHP_XDEBUG_IDEKEY=my "key"`)).To(BeEmpty())
+
+			Expect(iniPath).To(test.HaveContent(`zend_extension = xdebug.so
+xdebug.mode = debug
+xdebug.client_host = "10.0.0.1"
+xdebug.client_port = 9003
+xdebug.idekey = "my \"key\""
+
+; breakpoints & stepping need unoptimized opcodes, and Xdebug can't be used with the JIT
+opcache.optimization_level = 0
+opcache.jit = off
+`))
+		})
+
+		it("warns when BP_PHP_XDEBUG is true but Xdebug is not installed", func() {
+			Expect(os.Remove(filepath.Join(extensionDir, "xdebug.so"))).To(Succeed())
+
+			Expect(run("BP_PHP_XDEBUG=true")).To(ContainSubstring("Xdebug is not installed in " + extensionDir + ", it is not enabled"))
+			Expect(iniPath).NotTo(BeAnExistingFile())
+		})
+	})
+}
@@ -116,6 +116,17 @@ func testIntegration(t *testing.T, when spec.G, it spec.S) {
 			Expect(resp).To(ContainSubstring("SUCCESS"))
 		})
 
+		it("sets security headers on static files served by the built-in PHP server", func() {
+			app, err = PushSimpleApp("simple_app_php_security_headers", []string{phpDistURI, phpWebURI}, false)
+			Expect(err).NotTo(HaveOccurred())
+
+			body, headers, err := app.HTTPGet("/style.css")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(body).To(ContainSubstring("color: #333;"))
+			Expect(headers["Content-Type"]).To(ContainElement(ContainSubstring("text/css")))
+			Expect(headers["X-Content-Type-Options"]).To(Equal([]string{"nosniff"}))
+		})
+
 		it("serves a simple php page with httpd", func() {
 			app, err = PushSimpleApp("simple_app_httpd", []string{httpdURI, phpDistURI, phpWebURI}, false)
 			Expect(err).NotTo(HaveOccurred())
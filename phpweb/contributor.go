@@ -20,6 +20,7 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+	"os"
 	"path/filepath"
 
 	"github.com/paketo-buildpacks/php-web/config"
@@ -60,12 +61,9 @@ func NewContributor(context build.Build) (Contributor, bool, error) {
 	if err != nil {
 		return Contributor{}, false, err
 	}
-	context.Logger.Debug("Build Pack YAML: %v", buildpackYAML)
+	context.Logger.Debug("Build Pack YAML: %s", buildpackYAML)
 
-	err = config.WarnBuildpackYAML(context.Logger, context.Buildpack.Info.Version, context.Application.Root)
-	if err != nil {
-		return Contributor{}, false, err
-	}
+	config.WarnBuildpackYAML(context.Logger, context.Buildpack.Info.Version, buildpackYAML)
 
 	randomHash, err := generateRandomHash()
 	if err != nil {
@@ -78,25 +76,54 @@ func NewContributor(context build.Build) (Contributor, bool, error) {
 		return Contributor{}, false, err
 	}
 
+	// workers & the scheduler are supervised by procmgr, which only runs for a web app with httpd or nginx
+	if len(buildpackYAML.Config.Workers) > 0 || len(buildpackYAML.Config.Schedule) > 0 {
+		if !isWebApp {
+			context.Logger.BodyWarning("php.workers and php.schedule require a web app, they will not run next to a script")
+		} else if buildpackYAML.Config.WebServer != config.ApacheHttpd && buildpackYAML.Config.WebServer != config.Nginx {
+			context.Logger.BodyWarning("php.workers and php.schedule require the %s or %s web server, they will not run with %s", config.ApacheHttpd, config.Nginx, buildpackYAML.Config.WebServer)
+		}
+	}
+
+	procfile, err := config.LoadProcfile(context.Application.Root)
+	if err != nil {
+		return Contributor{}, false, err
+	}
+
+	processes, err := buildpackYAML.Config.Processes.Merge(procfile)
+	if err != nil {
+		return Contributor{}, false, err
+	}
+
 	featureConfig := features.FeatureConfig{
-		BpYAML:   buildpackYAML,
-		App:      context.Application,
-		IsWebApp: isWebApp,
-		Logger:   context.Logger,
+		BpYAML:    buildpackYAML,
+		App:       context.Application,
+		IsWebApp:  isWebApp,
+		Logger:    context.Logger,
+		Processes: processes,
 	}
 
+	// the web servers are configured before the tracer, so they need to know about it up front
+	datadog := features.NewDatadogFeature(featureConfig, context.Services, context.Platform.Root, filepath.Join(context.Buildpack.Root, "bin", "session_helper"), os.Environ())
+	featureConfig.LogTraceIDs = datadog.Enabled()
+
 	contributor := Contributor{
 		layers:   context.Layers,
 		logger:   context.Logger,
 		metadata: Metadata{"PHP Web", hex.EncodeToString(randomHash[:])},
 		features: []features.Feature{
 			features.NewPhpFeature(featureConfig),
+			features.NewXdebugFeature(featureConfig),
 			features.NewPhpWebServerFeature(featureConfig),
 			features.NewHttpdFeature(featureConfig),
 			features.NewNginxFeature(featureConfig),
+			features.NewPrecompressFeature(featureConfig),
 			features.NewPhpFpmFeature(featureConfig),
 			features.NewRedisFeature(featureConfig, context.Services, buildpackYAML.Config.Redis.SessionStoreServiceName, context.Platform.Root, filepath.Join(context.Buildpack.Root, "bin", "session_helper")),
 			features.NewMemcachedFeature(featureConfig, context.Services, buildpackYAML.Config.Memcached.SessionStoreServiceName, context.Platform.Root, filepath.Join(context.Buildpack.Root, "bin", "session_helper")),
+			features.NewNewRelicFeature(featureConfig, context.Services, context.Platform.Root, filepath.Join(context.Buildpack.Root, "bin", "session_helper")),
+			features.NewOpenTelemetryFeature(featureConfig, context.Services, context.Platform.Root, filepath.Join(context.Buildpack.Root, "bin", "session_helper")),
+			datadog,
 			features.NewProcMgrFeature(featureConfig, filepath.Join(context.Buildpack.Root, "bin", "procmgr")),
 			features.NewScriptsFeature(featureConfig),
 		},
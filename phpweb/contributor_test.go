@@ -17,6 +17,7 @@
 package phpweb
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -25,13 +26,17 @@ import (
 
 	"gopkg.in/yaml.v2"
 
+	bp "github.com/buildpack/libbuildpack/logger"
+
 	"github.com/cloudfoundry/libcfbuildpack/buildpackplan"
 	"github.com/paketo-buildpacks/php-web/config"
+	"github.com/paketo-buildpacks/php-web/features"
 
 	"github.com/paketo-buildpacks/php-web/procmgr"
 
 	"github.com/cloudfoundry/libcfbuildpack/helper"
 	"github.com/cloudfoundry/libcfbuildpack/layers"
+	"github.com/cloudfoundry/libcfbuildpack/logger"
 	"github.com/cloudfoundry/libcfbuildpack/test"
 	. "github.com/onsi/gomega"
 	"github.com/sclevine/spec"
@@ -65,6 +70,7 @@ func testContributor(t *testing.T, when spec.G, it spec.S) {
 		f.AddPlan(buildpackplan.Plan{Name: Dependency})
 
 		Expect(helper.WriteFile(filepath.Join(f.Build.Buildpack.Root, "bin", "procmgr"), os.ModePerm, "")).To(Succeed())
+		Expect(helper.WriteFile(filepath.Join(f.Build.Buildpack.Root, "bin", "session_helper"), os.ModePerm, "")).To(Succeed())
 	})
 
 	when("creating a new contributor", func() {
@@ -95,6 +101,7 @@ func testContributor(t *testing.T, when spec.G, it spec.S) {
 
 			Expect(f.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
 				Processes: []layers.Process{
+					{Type: "task", Command: features.DefaultTaskCommand, Direct: false},
 					{Type: "web", Command: fmt.Sprintf("procmgr %s", procFile), Direct: false},
 				},
 			}))
@@ -132,6 +139,7 @@ func testContributor(t *testing.T, when spec.G, it spec.S) {
 
 			Expect(f.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
 				Processes: []layers.Process{
+					{Type: "task", Command: features.DefaultTaskCommand, Direct: false},
 					{Type: "web", Command: fmt.Sprintf("procmgr %s", procFile), Direct: false},
 				},
 			}))
@@ -175,6 +183,7 @@ func testContributor(t *testing.T, when spec.G, it spec.S) {
 
 			Expect(f.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
 				Processes: []layers.Process{
+					{Type: "task", Command: features.DefaultTaskCommand, Direct: false},
 					{Type: "web", Command: fmt.Sprintf("procmgr %s", procFile), Direct: false},
 				},
 			}))
@@ -198,17 +207,17 @@ func testContributor(t *testing.T, when spec.G, it spec.S) {
 		})
 
 		when("the requested web server is not supported", func() {
-			it("does not provide a start command", func() {
-				c := CreateTestContributor(config.BuildpackYAML{
+			it("fails listing the supported web servers", func() {
+				bytes, err := yaml.Marshal(config.BuildpackYAML{
 					Config: config.Config{
 						WebServer: "notsupportedserver",
 					},
 				})
-				Expect(c.Contribute()).To(Succeed())
+				Expect(err).To(Not(HaveOccurred()))
+				Expect(helper.WriteFile(filepath.Join(f.Build.Application.Root, "buildpack.yml"), 0644, string(bytes))).To(Succeed())
 
-				phpLayer := f.Build.Layers.Layer(Dependency)
-				procFile := filepath.Join(phpLayer.Root, "procs.yml")
-				Expect(procFile).ToNot(BeAnExistingFile())
+				_, _, err = NewContributor(f.Build)
+				Expect(err).To(MatchError(`invalid php.webserver "notsupportedserver", must be one of: php-server, httpd, nginx`))
 			})
 		})
 	})
@@ -263,6 +272,7 @@ func testContributor(t *testing.T, when spec.G, it spec.S) {
 
 		when("it's not a web app", func() {
 			it("contributes a php.ini file & configures PHP to look at it for a script", func() {
+				Expect(helper.WriteFile(filepath.Join(f.Build.Application.Root, "app.php"), 0644, "")).To(Succeed())
 				c := CreateTestContributor(config.BuildpackYAML{})
 				layer := f.Build.Layers.Layer(Dependency)
 				Expect(c.Contribute()).To(Succeed())
@@ -270,6 +280,32 @@ func testContributor(t *testing.T, when spec.G, it spec.S) {
 				Expect(layer).To(test.HaveOverrideSharedEnvironment("PHPRC", filepath.Join(layer.Root, "etc")))
 				Expect(layer).To(test.HaveOverrideSharedEnvironment("PHP_INI_SCAN_DIR", filepath.Join(f.Build.Application.Root, ".php.ini.d")))
 			})
+
+			it("warns that workers and the schedule will not run, and only runs the script", func() {
+				buf := bytes.NewBuffer(nil)
+				f.Build.Logger = logger.Logger{Logger: bp.NewLogger(buf, buf)}
+				Expect(helper.WriteFile(filepath.Join(f.Build.Application.Root, "app.php"), 0644, "")).To(Succeed())
+
+				c := CreateTestContributor(config.BuildpackYAML{Config: config.Config{
+					WebServer: config.Nginx,
+					Workers:   config.Workers{"queue": config.Worker{Command: "php artisan queue:work"}},
+					Schedule:  []string{"* * * * * php artisan schedule:run"},
+				}})
+				Expect(c.Contribute()).To(Succeed())
+
+				Expect(buf.String()).To(ContainSubstring("php.workers and php.schedule require a web app, they will not run next to a script"))
+				Expect(f.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+					Processes: []layers.Process{
+						{Type: "task", Command: features.DefaultTaskCommand},
+						{Type: "web", Command: fmt.Sprintf("php %s", filepath.Join(f.Build.Application.Root, "app.php"))},
+					},
+				}))
+			})
+
+			it("fails the build when there is no script to run", func() {
+				c := CreateTestContributor(config.BuildpackYAML{})
+				Expect(c.Contribute()).To(MatchError(ContainSubstring("could not find a file to execute")))
+			})
 		})
 	})
 }
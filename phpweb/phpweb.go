@@ -1,32 +1,95 @@
 package phpweb
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/Masterminds/semver"
 	"github.com/paketo-buildpacks/php-web/config"
 
 	"github.com/cloudfoundry/libcfbuildpack/buildpack"
+	"github.com/cloudfoundry/libcfbuildpack/logger"
 )
 
 const (
 	// Dependency in the buildplan indicates that this is a web app
 	Dependency = "php-web"
+
+	// VersionSourceBuildpackYAML is the version source of `php.version` in `buildpack.yml`
+	VersionSourceBuildpackYAML = "buildpack.yml"
+
+	// VersionSourceEnv is the version source of the BP_PHP_VERSION environment variable
+	VersionSourceEnv = "BP_PHP_VERSION"
+
+	// VersionSourceComposerLock is the version source of the `php` platform requirement in `composer.lock`
+	VersionSourceComposerLock = "composer.lock"
+
+	// VersionSourceComposerJSON is the version source of the `php` requirement in `composer.json`
+	VersionSourceComposerJSON = "composer.json"
+
+	// VersionSourceDefault is the version source of the buildpack's default version
+	VersionSourceDefault = "default-versions"
 )
 
-// Version returns the selected version of PHP using the following precedence:
+// Version returns the selected version of PHP and where it came from, using the following precedence:
 //
 // 1. `php.version` from `buildpack.yml`
-// 2. Build Plan Version, if set by composer
-// 3. Buildpack Metadata "default_version"
-// 4. `*` which should pick latest version
-func Version(buildpack buildpack.Buildpack) string {
+// 2. `BP_PHP_VERSION`
+// 3. the `php` platform requirement in `composer.lock`, or else the `php` requirement in `composer.json`
+// 4. Buildpack Metadata "default_version"
+// 5. `*` which should pick latest version
+//
+// A composer file or requirement which can't be used is skipped with a warning. Versions required in the Build Plan
+// by other buildpacks, like composer, are merged with this one by the buildpack providing PHP, which uses the version
+// source to decide between them.
+func Version(buildpack buildpack.Buildpack, buildpackYAML config.BuildpackYAML, appRoot string, logger logger.Logger) (string, string, error) {
+	if buildpackYAML.Config.Version != "" {
+		return buildpackYAML.Config.Version, VersionSourceBuildpackYAML, nil
+	}
+
+	if version := os.Getenv("BP_PHP_VERSION"); version != "" {
+		if _, err := semver.NewConstraint(version); err != nil {
+			return "", "", fmt.Errorf("invalid BP_PHP_VERSION %q: %w", version, err)
+		}
+		return version, VersionSourceEnv, nil
+	}
+
+	composerLock, err := config.LoadComposerLock(appRoot)
+	if err != nil {
+		logger.BodyWarning("Ignoring composer.lock to pick the PHP version: %s", err)
+	} else if version, ok := composerVersion(logger, VersionSourceComposerLock, composerLock.Platform["php"]); ok {
+		return version, VersionSourceComposerLock, nil
+	}
+
+	composerJSON, err := config.LoadComposerJSON(appRoot)
+	if err != nil {
+		logger.BodyWarning("Ignoring composer.json to pick the PHP version: %s", err)
+	} else if version, ok := composerVersion(logger, VersionSourceComposerJSON, composerJSON.Require["php"]); ok {
+		return version, VersionSourceComposerJSON, nil
+	}
+
 	if version, ok := buildpack.Metadata["default_version"].(string); ok {
-		return version
+		return version, VersionSourceDefault, nil
+	}
+
+	return "*", VersionSourceDefault, nil
+}
+
+// composerVersion converts the `php` requirement of a composer file, warning when it's set but can't be converted
+func composerVersion(logger logger.Logger, source string, constraint string) (string, bool) {
+	if constraint == "" {
+		return "", false
+	}
+
+	version, err := config.ComposerConstraint(constraint)
+	if err != nil {
+		logger.BodyWarning("Ignoring the php requirement of %s, set php.version or BP_PHP_VERSION to pick the PHP version: %s", source, err)
+		return "", false
 	}
 
-	return "*"
+	return version, true
 }
 
 // LoadAvailablePHPExtensions locates available extensions and returns the list
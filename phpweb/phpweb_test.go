@@ -17,15 +17,19 @@
 package phpweb
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
 
 	bp "github.com/buildpack/libbuildpack/buildpack"
+	bplogger "github.com/buildpack/libbuildpack/logger"
 	"github.com/cloudfoundry/libcfbuildpack/buildpack"
 	"github.com/cloudfoundry/libcfbuildpack/logger"
 	"github.com/cloudfoundry/libcfbuildpack/test"
+	"github.com/paketo-buildpacks/php-web/config"
 	"github.com/sclevine/spec"
 	"github.com/sclevine/spec/report"
 
@@ -42,18 +46,112 @@ func testPHPWeb(t *testing.T, when spec.G, it spec.S) {
 	})
 
 	when("a version is set", func() {
+		var appRoot string
+
+		it.Before(func() {
+			var err error
+			appRoot, err = ioutil.TempDir("", "phpweb")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(appRoot)).To(Succeed())
+			Expect(os.Unsetenv("BP_PHP_VERSION")).To(Succeed())
+		})
+
+		it("uses php.version from buildpack.yml first", func() {
+			buildpack := buildpack.NewBuildpack(bp.Buildpack{Metadata: buildpack.Metadata{"default_version": "test-version"}}, logger.Logger{})
+			Expect(os.Setenv("BP_PHP_VERSION", "7.3.*")).To(Succeed())
+
+			version, source, err := Version(buildpack, config.BuildpackYAML{Config: config.Config{Version: "7.4.*"}}, appRoot, logger.Logger{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal("7.4.*"))
+			Expect(source).To(Equal(VersionSourceBuildpackYAML))
+		})
+
+		it("uses BP_PHP_VERSION before composer", func() {
+			buildpack := buildpack.NewBuildpack(bp.Buildpack{}, logger.Logger{})
+			Expect(os.Setenv("BP_PHP_VERSION", "7.3.*")).To(Succeed())
+			test.WriteFile(t, filepath.Join(appRoot, "composer.json"), `{"require": {"php": "^7.4"}}`)
+
+			version, source, err := Version(buildpack, config.BuildpackYAML{}, appRoot, logger.Logger{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal("7.3.*"))
+			Expect(source).To(Equal(VersionSourceEnv))
+		})
+
+		it("uses the platform requirement of composer.lock before composer.json", func() {
+			buildpack := buildpack.NewBuildpack(bp.Buildpack{}, logger.Logger{})
+			test.WriteFile(t, filepath.Join(appRoot, "composer.json"), `{"require": {"php": "^7.2"}}`)
+			test.WriteFile(t, filepath.Join(appRoot, "composer.lock"), `{"platform": {"php": "~7.4.0"}}`)
+
+			version, source, err := Version(buildpack, config.BuildpackYAML{}, appRoot, logger.Logger{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal("~7.4.0"))
+			Expect(source).To(Equal(VersionSourceComposerLock))
+		})
+
+		it("uses the requirement of composer.json", func() {
+			buildpack := buildpack.NewBuildpack(bp.Buildpack{Metadata: buildpack.Metadata{"default_version": "test-version"}}, logger.Logger{})
+			test.WriteFile(t, filepath.Join(appRoot, "composer.json"), `{"require": {"php": "^7.2 || ^8.0"}}`)
+			test.WriteFile(t, filepath.Join(appRoot, "composer.lock"), `{"platform": []}`)
+
+			version, source, err := Version(buildpack, config.BuildpackYAML{}, appRoot, logger.Logger{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal("^7.2 || ^8.0"))
+			Expect(source).To(Equal(VersionSourceComposerJSON))
+		})
+
+		it("fails when BP_PHP_VERSION is not a version constraint", func() {
+			buildpack := buildpack.NewBuildpack(bp.Buildpack{}, logger.Logger{})
+			Expect(os.Setenv("BP_PHP_VERSION", "seven")).To(Succeed())
+
+			_, _, err := Version(buildpack, config.BuildpackYAML{}, appRoot, logger.Logger{})
+			Expect(err).To(MatchError(ContainSubstring(`invalid BP_PHP_VERSION "seven"`)))
+		})
+
+		it("warns & uses the default version when the composer requirement can't be used", func() {
+			buildpack := buildpack.NewBuildpack(bp.Buildpack{Metadata: buildpack.Metadata{"default_version": "test-version"}}, logger.Logger{})
+			test.WriteFile(t, filepath.Join(appRoot, "composer.json"), `{"require": {"php": "dev-master"}}`)
+			buf := bytes.NewBuffer(nil)
+
+			version, source, err := Version(buildpack, config.BuildpackYAML{}, appRoot, logger.Logger{Logger: bplogger.NewLogger(buf, buf)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal("test-version"))
+			Expect(source).To(Equal(VersionSourceDefault))
+			Expect(buf.String()).To(ContainSubstring("Ignoring the php requirement of composer.json, set php.version or BP_PHP_VERSION to pick the PHP version"))
+		})
+
+		it("warns & skips a composer file which can't be parsed", func() {
+			buildpack := buildpack.NewBuildpack(bp.Buildpack{}, logger.Logger{})
+			test.WriteFile(t, filepath.Join(appRoot, "composer.json"), `{"require": {"php": "^7.4"}}`)
+			test.WriteFile(t, filepath.Join(appRoot, "composer.lock"), `{"platform": `)
+			buf := bytes.NewBuffer(nil)
+
+			version, source, err := Version(buildpack, config.BuildpackYAML{}, appRoot, logger.Logger{Logger: bplogger.NewLogger(buf, buf)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal("^7.4"))
+			Expect(source).To(Equal(VersionSourceComposerJSON))
+			Expect(buf.String()).To(ContainSubstring("Ignoring composer.lock to pick the PHP version: unable to parse composer.lock"))
+		})
+
 		it("uses buildpack default version if set", func() {
 			buildpack := buildpack.NewBuildpack(bp.Buildpack{Metadata: buildpack.Metadata{"default_version": "test-version"}}, logger.Logger{})
 
-			Expect(Version(buildpack)).To(Equal("test-version"))
+			version, source, err := Version(buildpack, config.BuildpackYAML{}, appRoot, logger.Logger{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal("test-version"))
+			Expect(source).To(Equal(VersionSourceDefault))
 		})
 
 		it("return `*` if none set", func() {
 			buildpack := buildpack.NewBuildpack(bp.Buildpack{}, logger.Logger{})
 
-			Expect(Version(buildpack)).To(Equal("*"))
+			version, source, err := Version(buildpack, config.BuildpackYAML{}, appRoot, logger.Logger{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal("*"))
+			Expect(source).To(Equal(VersionSourceDefault))
 		})
-
 	})
 
 	when("we need a list of PHP extensions", func() {
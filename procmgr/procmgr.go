@@ -18,6 +18,18 @@ type Procs struct {
 type Proc struct {
 	Command string
 	Args    []string
+
+	// Replicas is the number of copies of the process to run, defaults to one
+	Replicas int `yaml:"replicas,omitempty"`
+
+	// Restart runs the process again when it exits, instead of stopping all processes
+	Restart bool `yaml:"restart,omitempty"`
+
+	// Watch are directories, which are polled for changes to the configuration of the process
+	Watch []string `yaml:"watch,omitempty"`
+
+	// ReloadSignal is sent to the process when a file below one of the watched directories changes, e.g. `HUP`
+	ReloadSignal string `yaml:"reload_signal,omitempty"`
 }
 
 func ReadProcs(path string) (Procs, error) {
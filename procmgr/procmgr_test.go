@@ -36,7 +36,7 @@ func testProcmgrLib(t *testing.T, when spec.G, it spec.S) {
 		list, err := ReadProcs(procsFile)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(len(list.Processes)).To(Equal(2))
-		Expect(list.Processes["echo1"]).To(Equal(Proc{"echo", []string{"'Hello World!'"}}))
+		Expect(list.Processes["echo1"]).To(Equal(Proc{Command: "echo", Args: []string{"'Hello World!'"}}))
 	})
 
 	it("should if file does not exist", func() {
@@ -0,0 +1,52 @@
+package procmgr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+var reloadSignals = map[string]syscall.Signal{
+	"HUP":  syscall.SIGHUP,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+}
+
+// ParseReloadSignal parses the signal which makes a process reload its configuration, `HUP`, `USR1` or `USR2`, with or without the `SIG` prefix
+func ParseReloadSignal(name string) (syscall.Signal, error) {
+	signal, ok := reloadSignals[strings.TrimPrefix(strings.ToUpper(name), "SIG")]
+	if !ok {
+		return 0, fmt.Errorf("invalid reload signal %q, must be one of: HUP, USR1, USR2", name)
+	}
+	return signal, nil
+}
+
+// WatchState is a fingerprint of the files below the watched directories, which changes when a file is added, removed
+// or modified. Missing directories are skipped, so they can be created later.
+func WatchState(dirs []string) (string, error) {
+	var entries []string
+
+	for _, dir := range dirs {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if os.IsNotExist(err) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			if !info.IsDir() {
+				entries = append(entries, fmt.Sprintf("%s %d %d", path, info.Size(), info.ModTime().UnixNano()))
+			}
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	sort.Strings(entries)
+	return strings.Join(entries, "\n"), nil
+}
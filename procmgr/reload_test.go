@@ -0,0 +1,62 @@
+package procmgr
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitReload(t *testing.T) {
+	spec.Run(t, "Reload", testReload, spec.Report(report.Terminal{}))
+}
+
+func testReload(t *testing.T, when spec.G, it spec.S) {
+	it.Before(func() {
+		RegisterTestingT(t)
+	})
+
+	when("parsing a reload signal", func() {
+		it("accepts names with and without the SIG prefix", func() {
+			Expect(ParseReloadSignal("HUP")).To(Equal(syscall.SIGHUP))
+			Expect(ParseReloadSignal("SIGUSR2")).To(Equal(syscall.SIGUSR2))
+			Expect(ParseReloadSignal("usr1")).To(Equal(syscall.SIGUSR1))
+		})
+
+		it("rejects other signals", func() {
+			_, err := ParseReloadSignal("KILL")
+			Expect(err).To(MatchError(`invalid reload signal "KILL", must be one of: HUP, USR1, USR2`))
+		})
+	})
+
+	when("fingerprinting watched directories", func() {
+		it("changes when a file is added, modified or removed", func() {
+			dir := t.TempDir()
+			file := filepath.Join(dir, "nested", "custom.conf")
+			dirs := []string{dir, filepath.Join(dir, "missing")}
+
+			empty, err := WatchState(dirs)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(os.MkdirAll(filepath.Dir(file), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(file, []byte("a"), 0644)).To(Succeed())
+			added, err := WatchState(dirs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(added).ToNot(Equal(empty))
+
+			Expect(os.Chtimes(file, time.Now(), time.Now().Add(time.Minute))).To(Succeed())
+			modified, err := WatchState(dirs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(modified).ToNot(Equal(added))
+
+			Expect(os.Remove(file)).To(Succeed())
+			Expect(WatchState(dirs)).To(Equal(empty))
+		})
+	})
+}
@@ -0,0 +1,183 @@
+package procmgr
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry/libcfbuildpack/helper"
+)
+
+var (
+	schedulePattern      = regexp.MustCompile(`^(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s+(.+)$`)
+	scheduleMacroPattern = regexp.MustCompile(`^(@[a-z]+)\s+(.+)$`)
+
+	scheduleMacros = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// ScheduledJob is a command run by the procmgr scheduler at the minutes matching a crontab-style expression
+type ScheduledJob struct {
+	Command string
+
+	minutes, hours, days, months, weekdays uint64
+	anyDay, anyWeekday                     bool
+}
+
+// ParseScheduledJob parses a crontab-style line, `<minute> <hour> <day of month> <month> <day of week> <command>` or `@<macro> <command>`
+func ParseScheduledJob(line string) (ScheduledJob, error) {
+	line = strings.TrimSpace(line)
+
+	if matches := scheduleMacroPattern.FindStringSubmatch(line); matches != nil {
+		expression, ok := scheduleMacros[matches[1]]
+		if !ok {
+			return ScheduledJob{}, fmt.Errorf("unknown schedule macro %q", matches[1])
+		}
+		line = expression + " " + matches[2]
+	}
+
+	matches := schedulePattern.FindStringSubmatch(line)
+	if matches == nil {
+		return ScheduledJob{}, fmt.Errorf("invalid schedule %q, must be `<minute> <hour> <day of month> <month> <day of week> <command>`", line)
+	}
+
+	job := ScheduledJob{
+		Command:    matches[6],
+		anyDay:     strings.HasPrefix(matches[3], "*"),
+		anyWeekday: strings.HasPrefix(matches[5], "*"),
+	}
+
+	var err error
+	if job.minutes, err = parseScheduleField(matches[1], 0, 59); err != nil {
+		return ScheduledJob{}, fmt.Errorf("invalid minute in schedule %q: %w", line, err)
+	}
+	if job.hours, err = parseScheduleField(matches[2], 0, 23); err != nil {
+		return ScheduledJob{}, fmt.Errorf("invalid hour in schedule %q: %w", line, err)
+	}
+	if job.days, err = parseScheduleField(matches[3], 1, 31); err != nil {
+		return ScheduledJob{}, fmt.Errorf("invalid day of month in schedule %q: %w", line, err)
+	}
+	if job.months, err = parseScheduleField(matches[4], 1, 12); err != nil {
+		return ScheduledJob{}, fmt.Errorf("invalid month in schedule %q: %w", line, err)
+	}
+	if job.weekdays, err = parseScheduleField(matches[5], 0, 7); err != nil {
+		return ScheduledJob{}, fmt.Errorf("invalid day of week in schedule %q: %w", line, err)
+	}
+
+	// both 0 & 7 are Sunday
+	if job.weekdays&(1<<7) != 0 {
+		job.weekdays |= 1
+	}
+
+	return job, nil
+}
+
+// Matches checks if the job should run at the minute of t. Like cron, when both the day of month & day of week are
+// restricted the job runs when either matches.
+func (j ScheduledJob) Matches(t time.Time) bool {
+	if !hasBit(j.minutes, t.Minute()) || !hasBit(j.hours, t.Hour()) || !hasBit(j.months, int(t.Month())) {
+		return false
+	}
+
+	day, weekday := hasBit(j.days, t.Day()), hasBit(j.weekdays, int(t.Weekday()))
+	if j.anyDay || j.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
+
+// parseScheduleField parses a comma separated list of `*`, `<n>` or `<n>-<m>`, each optionally followed by `/<step>`
+func parseScheduleField(field string, min int, max int) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		step, stepped := 1, false
+		if i := strings.Index(part, "/"); i >= 0 {
+			stepped = true
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q", part[i+1:])
+			}
+			part = part[:i]
+		}
+
+		low, high := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if low, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("invalid value %q", bounds[0])
+			}
+			if high, err = strconv.Atoi(bounds[1]); err != nil {
+				return 0, fmt.Errorf("invalid value %q", bounds[1])
+			}
+		default:
+			var err error
+			if low, err = strconv.Atoi(part); err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			// `<n>/<step>` runs from n until the maximum
+			if !stepped {
+				high = low
+			}
+		}
+
+		if low < min || high > max || low > high {
+			return 0, fmt.Errorf("%q is out of range %d-%d", part, min, max)
+		}
+
+		for value := low; value <= high; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+
+	return bits, nil
+}
+
+func hasBit(bits uint64, value int) bool {
+	return bits&(1<<uint(value)) != 0
+}
+
+// ReadSchedule reads the jobs of a crontab-style file, one per line, skipping blank lines & `#` comments
+func ReadSchedule(path string) ([]ScheduledJob, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open schedule: %w", err)
+	}
+	defer file.Close()
+
+	var jobs []ScheduledJob
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		job, err := ParseScheduledJob(line)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, scanner.Err()
+}
+
+// WriteSchedule writes crontab-style lines, to be run by the procmgr scheduler
+func WriteSchedule(path string, lines []string) error {
+	return helper.WriteFile(path, 0644, strings.Join(lines, "\n")+"\n")
+}
@@ -0,0 +1,104 @@
+package procmgr
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudfoundry/libcfbuildpack/helper"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitSchedule(t *testing.T) {
+	spec.Run(t, "Schedule", testSchedule, spec.Report(report.Terminal{}))
+}
+
+func testSchedule(t *testing.T, when spec.G, it spec.S) {
+	it.Before(func() {
+		RegisterTestingT(t)
+	})
+
+	at := func(month time.Month, day int, hour int, minute int) time.Time {
+		return time.Date(2020, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	when("parsing a schedule", func() {
+		it("keeps the command as-is", func() {
+			job, err := ParseScheduledJob("*/5 * * * * php artisan  schedule:run --verbose")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(job.Command).To(Equal("php artisan  schedule:run --verbose"))
+		})
+
+		it("rejects a schedule with missing fields", func() {
+			_, err := ParseScheduledJob("* * * php artisan")
+			Expect(err).To(MatchError(ContainSubstring(`invalid schedule "* * * php artisan"`)))
+		})
+
+		it("rejects out of range values", func() {
+			_, err := ParseScheduledJob("0 24 * * * php artisan")
+			Expect(err).To(MatchError(ContainSubstring(`invalid hour in schedule "0 24 * * * php artisan": "24" is out of range 0-23`)))
+		})
+
+		it("rejects unknown macros", func() {
+			_, err := ParseScheduledJob("@sometimes php artisan")
+			Expect(err).To(MatchError(`unknown schedule macro "@sometimes"`))
+		})
+	})
+
+	when("matching times", func() {
+		it("matches steps, ranges and lists", func() {
+			job, err := ParseScheduledJob("*/15 9-17 * * 1,3 echo")
+			Expect(err).ToNot(HaveOccurred())
+
+			// 2020-01-01 is a Wednesday
+			Expect(job.Matches(at(time.January, 1, 9, 30))).To(BeTrue())
+			Expect(job.Matches(at(time.January, 1, 9, 31))).To(BeFalse())
+			Expect(job.Matches(at(time.January, 1, 18, 0))).To(BeFalse())
+			Expect(job.Matches(at(time.January, 2, 9, 30))).To(BeFalse())
+		})
+
+		it("starts a stepped value at that value", func() {
+			job, err := ParseScheduledJob("5/20 * * * * echo")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(job.Matches(at(time.January, 1, 0, 45))).To(BeTrue())
+			Expect(job.Matches(at(time.January, 1, 0, 40))).To(BeFalse())
+		})
+
+		it("matches either the day of month or day of week, when both are restricted", func() {
+			job, err := ParseScheduledJob("0 0 15 * 7 echo")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(job.Matches(at(time.January, 15, 0, 0))).To(BeTrue())
+			Expect(job.Matches(at(time.January, 5, 0, 0))).To(BeTrue()) // a Sunday
+			Expect(job.Matches(at(time.January, 6, 0, 0))).To(BeFalse())
+		})
+
+		it("expands macros", func() {
+			job, err := ParseScheduledJob("@daily echo")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(job.Matches(at(time.March, 3, 0, 0))).To(BeTrue())
+			Expect(job.Matches(at(time.March, 3, 1, 0))).To(BeFalse())
+		})
+	})
+
+	it("writes & reads a schedule file", func() {
+		tmp, err := ioutil.TempDir("", "schedule")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmp)
+
+		path := filepath.Join(tmp, "schedule")
+		Expect(WriteSchedule(path, []string{"* * * * * echo one", "@hourly echo two"})).To(Succeed())
+		Expect(helper.FileExists(path)).To(BeTrue())
+
+		jobs, err := ReadSchedule(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(jobs).To(HaveLen(2))
+		Expect(jobs[1].Command).To(Equal("echo two"))
+	})
+}